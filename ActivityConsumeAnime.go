@@ -1,6 +1,10 @@
 package arn
 
-import "sort"
+import (
+	"sort"
+
+	"github.com/aerogo/nano"
+)
 
 // ActivityConsumeAnime is a user activity that consumes anime.
 type ActivityConsumeAnime struct {
@@ -57,6 +61,21 @@ func (user *User) LastActivityConsumeAnime(animeID string) *ActivityConsumeAnime
 	return activities[0]
 }
 
+// StreamActivityConsumeAnimes returns a stream of all ActivityConsumeAnime objects.
+func StreamActivityConsumeAnimes() chan *ActivityConsumeAnime {
+	channel := make(chan *ActivityConsumeAnime, nano.ChannelBufferSize)
+
+	go func() {
+		for obj := range DB.All("ActivityConsumeAnime") {
+			channel <- obj.(*ActivityConsumeAnime)
+		}
+
+		close(channel)
+	}()
+
+	return channel
+}
+
 // FilterActivitiesConsumeAnime filters all anime consumption activities by a custom function.
 func FilterActivitiesConsumeAnime(filter func(*ActivityConsumeAnime) bool) []*ActivityConsumeAnime {
 	var filtered []*ActivityConsumeAnime
@@ -2,42 +2,96 @@ package arn
 
 import (
 	"time"
+
+	"github.com/animenotifier/arn/validate"
+)
+
+const (
+	// AiringDateSourceEditor is the source of airing dates entered by editors.
+	AiringDateSourceEditor = "editor"
+
+	// AiringDateHumanFormat is the human readable format of airing dates.
+	AiringDateHumanFormat = "Mon, 02 Jan 2006"
+
+	// AiringTimeHumanFormat is the human readable format of airing times.
+	AiringTimeHumanFormat = "15:04:05 MST"
 )
 
 // AiringDate represents the airing date of an anime.
+// Estimated dates have been predicted from the broadcast pattern instead of being confirmed by a source.
+// Dates saved before sources were recorded have neither a source nor the estimated flag.
 type AiringDate struct {
-	Start string `json:"start" editable:"true"`
-	End   string `json:"end" editable:"true"`
+	Start      string  `json:"start" editable:"true"`
+	End        string  `json:"end" editable:"true"`
+	Estimated  bool    `json:"estimated" editable:"true"`
+	Confidence float64 `json:"confidence"`
+	Source     string  `json:"source" editable:"true"`
+}
+
+// Confirmed tells you whether the start has been confirmed by a source.
+func (airing *AiringDate) Confirmed() bool {
+	return !airing.Estimated && airing.Source != "" && validate.DateTime(airing.Start)
+}
+
+// StartTime returns the start time in the given time zone.
+// Returns the zero time if the start is not a valid date.
+func (airing *AiringDate) StartTime(location *time.Location) time.Time {
+	return parseAiringTime(airing.Start, location)
+}
+
+// EndTime returns the end time in the given time zone.
+// Returns the zero time if the end is not a valid date.
+func (airing *AiringDate) EndTime(location *time.Location) time.Time {
+	return parseAiringTime(airing.End, location)
 }
 
 // StartDateHuman returns the start date of the anime in human readable form.
 func (airing *AiringDate) StartDateHuman() string {
-	t, _ := time.Parse(time.RFC3339, airing.Start)
-	humanReadable := t.Format(time.RFC1123)
-
-	return humanReadable[:len("Thu, 25 May 2017")]
+	return airing.StartDateHumanIn(time.UTC)
 }
 
 // EndDateHuman returns the end date of the anime in human readable form.
 func (airing *AiringDate) EndDateHuman() string {
-	t, _ := time.Parse(time.RFC3339, airing.End)
-	humanReadable := t.Format(time.RFC1123)
-
-	return humanReadable[:len("Thu, 25 May 2017")]
+	return airing.EndDateHumanIn(time.UTC)
 }
 
 // StartTimeHuman returns the start time of the anime in human readable form.
 func (airing *AiringDate) StartTimeHuman() string {
-	t, _ := time.Parse(time.RFC3339, airing.Start)
-	humanReadable := t.Format(time.RFC1123)
-
-	return humanReadable[len("Thu, 25 May 2017 "):]
+	return airing.StartTimeHumanIn(time.UTC)
 }
 
 // EndTimeHuman returns the end time of the anime in human readable form.
 func (airing *AiringDate) EndTimeHuman() string {
-	t, _ := time.Parse(time.RFC3339, airing.End)
-	humanReadable := t.Format(time.RFC1123)
+	return airing.EndTimeHumanIn(time.UTC)
+}
+
+// StartDateHumanIn returns the start date in the given time zone in human readable form.
+func (airing *AiringDate) StartDateHumanIn(location *time.Location) string {
+	return airing.StartTime(location).Format(AiringDateHumanFormat)
+}
+
+// EndDateHumanIn returns the end date in the given time zone in human readable form.
+func (airing *AiringDate) EndDateHumanIn(location *time.Location) string {
+	return airing.EndTime(location).Format(AiringDateHumanFormat)
+}
+
+// StartTimeHumanIn returns the start time in the given time zone in human readable form.
+func (airing *AiringDate) StartTimeHumanIn(location *time.Location) string {
+	return airing.StartTime(location).Format(AiringTimeHumanFormat)
+}
+
+// EndTimeHumanIn returns the end time in the given time zone in human readable form.
+func (airing *AiringDate) EndTimeHumanIn(location *time.Location) string {
+	return airing.EndTime(location).Format(AiringTimeHumanFormat)
+}
+
+// parseAiringTime parses an RFC 3339 date and converts it to the given time zone.
+func parseAiringTime(date string, location *time.Location) time.Time {
+	t, err := time.Parse(time.RFC3339, date)
+
+	if err != nil {
+		return time.Time{}
+	}
 
-	return humanReadable[len("Thu, 25 May 2017 "):]
+	return t.In(location)
 }
@@ -0,0 +1,278 @@
+package arn
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/animenotifier/arn/validate"
+)
+
+const (
+	// AiringInterval is the time between two regular broadcasts.
+	AiringInterval = 7 * 24 * time.Hour
+
+	// airingWeeklyUncertainty is the chance of an unexpected schedule change per week.
+	airingWeeklyUncertainty = 0.05
+)
+
+// AiringPredictor predicts missing airing dates from the broadcast pattern of the confirmed episodes.
+type AiringPredictor struct {
+	// Weekday and TimeOfDay are the time slot of the show in the time zone of the broadcaster.
+	Weekday   time.Weekday
+	TimeOfDay time.Duration
+	TimeZone  *time.Location
+
+	// Samples is the number of confirmed airing dates.
+	Samples int
+
+	// Regularity is the share of confirmed airing dates in the time slot.
+	Regularity float64
+
+	// BreakRate is the share of weeks without a broadcast between consecutive episodes.
+	BreakRate float64
+
+	// confirmed contains the confirmed episodes sorted by number.
+	confirmed []*AnimeEpisode
+}
+
+// airingSlot is a weekday and time of day.
+type airingSlot struct {
+	weekday   time.Weekday
+	timeOfDay time.Duration
+}
+
+// NewAiringPredictor learns the broadcast pattern of the episodes.
+// Only confirmed airing dates are taken into account, estimates are ignored.
+func NewAiringPredictor(episodes []*AnimeEpisode) *AiringPredictor {
+	predictor := &AiringPredictor{
+		TimeZone: time.UTC,
+	}
+
+	for _, episode := range episodes {
+		if episode.AiringDate.Confirmed() {
+			predictor.confirmed = append(predictor.confirmed, episode)
+		}
+	}
+
+	sort.SliceStable(predictor.confirmed, func(i, j int) bool {
+		return predictor.confirmed[i].Number < predictor.confirmed[j].Number
+	})
+
+	predictor.Samples = len(predictor.confirmed)
+
+	if predictor.Samples == 0 {
+		return predictor
+	}
+
+	// Broadcasters publish their dates in their local time zone
+	last := predictor.confirmed[len(predictor.confirmed)-1]
+	_, offset := predictor.confirmedStart(last).Zone()
+	predictor.TimeZone = FixedTimeZone(offset)
+
+	// Find the most common time slot, ties are won by the later episodes
+	counts := map[airingSlot]int{}
+	best := 0
+
+	for _, episode := range predictor.confirmed {
+		slot := predictor.slot(predictor.confirmedStart(episode))
+		counts[slot]++
+
+		if counts[slot] >= best {
+			best = counts[slot]
+			predictor.Weekday = slot.weekday
+			predictor.TimeOfDay = slot.timeOfDay
+		}
+	}
+
+	predictor.Regularity = float64(best) / float64(predictor.Samples)
+
+	// Weeks without broadcast between consecutive episode numbers are breaks
+	weeks := 0
+	breaks := 0
+
+	for i := 1; i < len(predictor.confirmed); i++ {
+		previous := predictor.confirmed[i-1]
+		episode := predictor.confirmed[i]
+
+		if episode.Number != previous.Number+1 {
+			continue
+		}
+
+		gap := weeksBetween(predictor.confirmedStart(previous), predictor.confirmedStart(episode))
+
+		if gap < 1 {
+			continue
+		}
+
+		weeks += gap
+		breaks += gap - 1
+	}
+
+	if weeks > 0 {
+		predictor.BreakRate = float64(breaks) / float64(weeks)
+	}
+
+	return predictor
+}
+
+// Apply sets estimated airing dates on all episodes without airing date and replaces previous estimates.
+// Dates without a source are kept because they might have been entered by hand,
+// but they're not used for learning. Episodes that can't be predicted lose their estimates.
+// The end times are calculated from the episode duration if they're missing.
+func (predictor *AiringPredictor) Apply(episodes []*AnimeEpisode, episodeDuration time.Duration) {
+	for _, episode := range episodes {
+		airing := &episode.AiringDate
+
+		if !airing.Estimated && validate.DateTime(airing.Start) {
+			start, _ := time.Parse(time.RFC3339, airing.Start)
+			end, err := time.Parse(time.RFC3339, airing.End)
+
+			if err != nil || !end.After(start) {
+				airing.End = start.Add(episodeDuration).Format(time.RFC3339)
+			}
+
+			continue
+		}
+
+		start, confidence, ok := predictor.Predict(episode.Number)
+
+		if !ok {
+			if airing.Estimated {
+				*airing = AiringDate{}
+			}
+
+			continue
+		}
+
+		airing.Start = start.Format(time.RFC3339)
+		airing.End = start.Add(episodeDuration).Format(time.RFC3339)
+		airing.Estimated = true
+		airing.Confidence = confidence
+		airing.Source = ""
+	}
+}
+
+// Predict returns the estimated airing date of the episode number and the confidence between 0 and 1.
+// Returns false if there are no confirmed episodes to learn from.
+func (predictor *AiringPredictor) Predict(number int) (time.Time, float64, bool) {
+	if predictor.Samples == 0 {
+		return time.Time{}, 0, false
+	}
+
+	var previous *AnimeEpisode
+	var next *AnimeEpisode
+
+	for _, episode := range predictor.confirmed {
+		if episode.Number < number {
+			previous = episode
+			continue
+		}
+
+		if episode.Number > number {
+			next = episode
+			break
+		}
+
+		// Confirmed
+		return predictor.confirmedStart(episode), 1, true
+	}
+
+	switch {
+	case previous != nil && next != nil:
+		return predictor.predictBetween(previous, next, number)
+
+	case previous != nil:
+		weeks := number - previous.Number
+		start := predictor.nextSlot(predictor.confirmedStart(previous)).Add(time.Duration(weeks-1) * AiringInterval)
+		return start, predictor.confidence(weeks), true
+
+	default:
+		weeks := next.Number - number
+		start := predictor.previousSlot(predictor.confirmedStart(next)).Add(-time.Duration(weeks-1) * AiringInterval)
+		return start, predictor.confidence(weeks), true
+	}
+}
+
+// predictBetween predicts an episode between two confirmed episodes.
+// If there are more weeks than episodes between them, the episodes are assumed to air
+// weekly after the previous episode and the breaks to be at the end.
+// Otherwise several episodes air in the same week and the dates are interpolated.
+func (predictor *AiringPredictor) predictBetween(previous *AnimeEpisode, next *AnimeEpisode, number int) (time.Time, float64, bool) {
+	previousStart := predictor.confirmedStart(previous)
+	nextStart := predictor.confirmedStart(next)
+	episodes := next.Number - previous.Number
+	offset := number - previous.Number
+	distance := offset
+
+	if next.Number-number < distance {
+		distance = next.Number - number
+	}
+
+	if weeksBetween(previousStart, nextStart) >= episodes {
+		start := previousStart.Add(time.Duration(offset) * AiringInterval)
+		return start, predictor.confidence(distance), true
+	}
+
+	step := nextStart.Sub(previousStart) / time.Duration(episodes)
+	start := previousStart.Add(step * time.Duration(offset))
+	return start, predictor.confidence(distance) * 0.5, true
+}
+
+// confidence returns the confidence of a prediction the given number of weeks away from a confirmed date.
+// It grows with the number of samples and the regularity of the time slot
+// and shrinks with every week that could contain a break or an unannounced change.
+func (predictor *AiringPredictor) confidence(weeks int) float64 {
+	samples := math.Min(float64(predictor.Samples), 4) / 4
+	weekly := (1 - predictor.BreakRate) * (1 - airingWeeklyUncertainty)
+	confidence := predictor.Regularity * samples * math.Pow(weekly, float64(weeks))
+	return math.Round(confidence*100) / 100
+}
+
+// confirmedStart returns the confirmed start time of the episode.
+func (predictor *AiringPredictor) confirmedStart(episode *AnimeEpisode) time.Time {
+	start, _ := time.Parse(time.RFC3339, episode.AiringDate.Start)
+	return start
+}
+
+// slot returns the weekday and time of day in the time zone of the broadcaster.
+func (predictor *AiringPredictor) slot(t time.Time) airingSlot {
+	t = t.In(predictor.TimeZone)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, predictor.TimeZone)
+
+	return airingSlot{
+		weekday:   t.Weekday(),
+		timeOfDay: t.Sub(midnight),
+	}
+}
+
+// nextSlot returns the first time slot at least one day after the given time.
+func (predictor *AiringPredictor) nextSlot(t time.Time) time.Time {
+	t = t.In(predictor.TimeZone)
+	days := (int(predictor.Weekday) - int(t.Weekday()) + 7) % 7
+
+	if days == 0 {
+		days = 7
+	}
+
+	year, month, day := t.Date()
+	return time.Date(year, month, day+days, 0, 0, 0, 0, predictor.TimeZone).Add(predictor.TimeOfDay)
+}
+
+// previousSlot returns the last time slot at least one day before the given time.
+func (predictor *AiringPredictor) previousSlot(t time.Time) time.Time {
+	t = t.In(predictor.TimeZone)
+	days := (int(t.Weekday()) - int(predictor.Weekday) + 7) % 7
+
+	if days == 0 {
+		days = 7
+	}
+
+	year, month, day := t.Date()
+	return time.Date(year, month, day-days, 0, 0, 0, 0, predictor.TimeZone).Add(predictor.TimeOfDay)
+}
+
+// weeksBetween returns the number of weeks between two times, rounded to full weeks.
+func weeksBetween(a time.Time, b time.Time) int {
+	return int(math.Round(float64(b.Sub(a)) / float64(AiringInterval)))
+}
@@ -0,0 +1,187 @@
+package arn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAiringEpisodes(starts ...string) []*arn.AnimeEpisode {
+	episodes := make([]*arn.AnimeEpisode, 0, len(starts))
+
+	for index, start := range starts {
+		episode := arn.NewAnimeEpisode()
+		episode.Number = index + 1
+		episode.AiringDate.Start = start
+
+		if start != "" {
+			episode.AiringDate.Source = "shoboi"
+		}
+
+		episodes = append(episodes, episode)
+	}
+
+	return episodes
+}
+
+func TestAiringPredictorWeekly(t *testing.T) {
+	episodes := newTestAiringEpisodes(
+		"2019-04-06T01:05:00+09:00",
+		"2019-04-13T01:05:00+09:00",
+		"2019-04-20T01:05:00+09:00",
+
+		// Holiday break
+		"2019-05-04T01:05:00+09:00",
+		"",
+		"",
+	)
+
+	predictor := arn.NewAiringPredictor(episodes)
+	assert.Equal(t, time.Saturday, predictor.Weekday)
+	assert.Equal(t, time.Hour+5*time.Minute, predictor.TimeOfDay)
+	assert.Equal(t, 4, predictor.Samples)
+	assert.Equal(t, 1.0, predictor.Regularity)
+	assert.Equal(t, 0.25, predictor.BreakRate)
+
+	predictor.Apply(episodes, 24*time.Minute)
+
+	// Confirmed dates get an end time, but are not estimates
+	assert.False(t, episodes[0].AiringDate.Estimated)
+	assert.Equal(t, "2019-04-06T01:29:00+09:00", episodes[0].AiringDate.End)
+
+	fifth := episodes[4].AiringDate
+	assert.True(t, fifth.Estimated)
+	assert.Equal(t, "2019-05-11T01:05:00+09:00", fifth.Start)
+	assert.Equal(t, "2019-05-11T01:29:00+09:00", fifth.End)
+
+	sixth := episodes[5].AiringDate
+	assert.True(t, sixth.Estimated)
+	assert.Equal(t, "2019-05-18T01:05:00+09:00", sixth.Start)
+
+	// Predictions further away are less certain
+	assert.True(t, fifth.Confidence > sixth.Confidence)
+	assert.True(t, fifth.Confidence < 1)
+	assert.True(t, sixth.Confidence > 0)
+}
+
+func TestAiringPredictorTimeSlot(t *testing.T) {
+	episodes := newTestAiringEpisodes(
+		"2019-04-06T15:00:00Z",
+		"2019-04-13T15:00:00Z",
+
+		// Delayed by a sports event
+		"2019-04-21T16:30:00Z",
+		"",
+	)
+
+	predictor := arn.NewAiringPredictor(episodes)
+	predictor.Apply(episodes, 30*time.Minute)
+
+	// Back in the regular time slot
+	assert.Equal(t, "2019-04-27T15:00:00Z", episodes[3].AiringDate.Start)
+	assert.InDelta(t, 2.0/3.0, predictor.Regularity, 0.001)
+}
+
+func TestAiringPredictorBetween(t *testing.T) {
+	// Two weeks break between episode 1 and 4
+	episodes := newTestAiringEpisodes(
+		"2019-04-01T12:00:00Z",
+		"",
+		"",
+		"2019-04-29T12:00:00Z",
+		"",
+	)
+
+	arn.NewAiringPredictor(episodes).Apply(episodes, 30*time.Minute)
+	assert.Equal(t, "2019-04-08T12:00:00Z", episodes[1].AiringDate.Start)
+	assert.Equal(t, "2019-04-15T12:00:00Z", episodes[2].AiringDate.Start)
+
+	// Several episodes per week
+	episodes = newTestAiringEpisodes(
+		"2019-04-01T12:00:00Z",
+		"",
+		"2019-04-03T12:00:00Z",
+	)
+
+	arn.NewAiringPredictor(episodes).Apply(episodes, 30*time.Minute)
+	assert.Equal(t, "2019-04-02T12:00:00Z", episodes[1].AiringDate.Start)
+	assert.True(t, episodes[1].AiringDate.Estimated)
+}
+
+func TestAiringPredictorEstimates(t *testing.T) {
+	episodes := newTestAiringEpisodes(
+		"2019-04-06T15:00:00Z",
+		"2019-04-20T15:00:00Z",
+	)
+
+	// Old estimates are not used for learning and replaced
+	episodes[1].AiringDate.Estimated = true
+	arn.NewAiringPredictor(episodes).Apply(episodes, 30*time.Minute)
+	assert.Equal(t, "2019-04-13T15:00:00Z", episodes[1].AiringDate.Start)
+
+	// Confirmed dates from a source replace the estimate
+	confirmed := arn.NewAnimeEpisode()
+	confirmed.Number = 2
+	confirmed.AiringDate.Start = "2019-04-14T15:00:00Z"
+	episodes[1].Merge(confirmed)
+	assert.False(t, episodes[1].AiringDate.Estimated)
+	assert.Equal(t, 0.0, episodes[1].AiringDate.Confidence)
+
+	// Without confirmed dates nothing can be predicted
+	episodes = newTestAiringEpisodes("", "2019-04-20T15:00:00Z")
+	episodes[1].AiringDate.Estimated = true
+	arn.NewAiringPredictor(episodes).Apply(episodes, 30*time.Minute)
+	assert.Equal(t, "", episodes[1].AiringDate.Start)
+	assert.False(t, episodes[1].AiringDate.Estimated)
+}
+
+func TestAiringPredictorWithoutSource(t *testing.T) {
+	episodes := newTestAiringEpisodes(
+		"2019-04-06T15:00:00Z",
+		"2019-04-20T15:00:00Z",
+		"2019-04-27T15:00:00Z",
+		"",
+	)
+
+	// Dates saved before sources were recorded are not used for learning, but kept
+	episodes[1].AiringDate.Source = ""
+	predictor := arn.NewAiringPredictor(episodes)
+	assert.Equal(t, 2, predictor.Samples)
+	assert.Equal(t, 0.0, predictor.BreakRate)
+
+	predictor.Apply(episodes, 30*time.Minute)
+	assert.Equal(t, "2019-04-20T15:00:00Z", episodes[1].AiringDate.Start)
+	assert.Equal(t, "2019-04-20T15:30:00Z", episodes[1].AiringDate.End)
+	assert.False(t, episodes[1].AiringDate.Estimated)
+	assert.True(t, episodes[3].AiringDate.Estimated)
+
+	// They're also kept if nothing can be predicted
+	episodes = newTestAiringEpisodes("2019-04-06T15:00:00Z")
+	episodes[0].AiringDate.Source = ""
+	arn.NewAiringPredictor(episodes).Apply(episodes, 30*time.Minute)
+	assert.Equal(t, "2019-04-06T15:00:00Z", episodes[0].AiringDate.Start)
+	assert.False(t, episodes[0].AiringDate.Estimated)
+}
+
+func TestAiringPredictorEditorDates(t *testing.T) {
+	episodes := newTestAiringEpisodes(
+		"2020-01-01T15:00:00Z",
+		"2020-01-08T15:00:00Z",
+		"2020-01-22T18:00:00Z",
+		"",
+	)
+
+	// Dates entered by editors are confirmed and used for learning
+	episodes[2].AiringDate.Source = arn.AiringDateSourceEditor
+	assert.True(t, episodes[2].AiringDate.Confirmed())
+
+	predictor := arn.NewAiringPredictor(episodes)
+	assert.Equal(t, 3, predictor.Samples)
+
+	predictor.Apply(episodes, 30*time.Minute)
+	assert.Equal(t, "2020-01-22T18:00:00Z", episodes[2].AiringDate.Start)
+	assert.False(t, episodes[2].AiringDate.Estimated)
+	assert.True(t, episodes[3].AiringDate.Estimated)
+}
@@ -0,0 +1,155 @@
+package arn
+
+import (
+	"sort"
+	"time"
+)
+
+// AiringScheduleDays is the number of days in an airing schedule.
+const AiringScheduleDays = 7
+
+// AiringSchedule is the weekly schedule of airing episodes in the time zone of a user.
+type AiringSchedule struct {
+	TimeZone *time.Location
+	Days     []*AiringScheduleDay
+}
+
+// AiringScheduleDay contains the episodes airing on a single local day.
+type AiringScheduleDay struct {
+	Date    time.Time
+	Entries []*AiringScheduleEntry
+}
+
+// AiringScheduleEntry is a single episode in the airing schedule.
+// The start and end times are in the time zone of the schedule.
+type AiringScheduleEntry struct {
+	Anime   *Anime
+	Episode *AnimeEpisode
+	Start   time.Time
+	End     time.Time
+}
+
+// NewAiringSchedule creates the schedule for the 7 local days starting with the day of "now".
+// Episodes are grouped by the local day they start airing on.
+// Episodes that started on the day before and are still airing at midnight appear on the first day.
+func NewAiringSchedule(episodes []*UpcomingEpisode, timeZone *time.Location, now time.Time) *AiringSchedule {
+	schedule := &AiringSchedule{
+		TimeZone: timeZone,
+		Days:     make([]*AiringScheduleDay, AiringScheduleDays),
+	}
+
+	now = now.In(timeZone)
+	year, month, day := now.Date()
+
+	// Days are created from the calendar date instead of adding 24 hours
+	// because days with daylight saving time transitions are shorter or longer.
+	for i := range schedule.Days {
+		schedule.Days[i] = &AiringScheduleDay{
+			Date:    time.Date(year, month, day+i, 0, 0, 0, 0, timeZone),
+			Entries: []*AiringScheduleEntry{},
+		}
+	}
+
+	weekStart := schedule.Days[0].Date
+	weekEnd := time.Date(year, month, day+AiringScheduleDays, 0, 0, 0, 0, timeZone)
+
+	for _, upcoming := range episodes {
+		start := upcoming.Episode.AiringDate.StartTime(timeZone)
+
+		if start.IsZero() {
+			continue
+		}
+
+		end := upcoming.Episode.AiringDate.EndTime(timeZone)
+
+		if !end.After(start) {
+			end = start.Add(upcoming.Anime.EpisodeDuration())
+		}
+
+		if !end.After(weekStart) || !start.Before(weekEnd) {
+			continue
+		}
+
+		entry := &AiringScheduleEntry{
+			Anime:   upcoming.Anime,
+			Episode: upcoming.Episode,
+			Start:   start,
+			End:     end,
+		}
+
+		scheduleDay := schedule.Day(start)
+
+		if scheduleDay == nil {
+			scheduleDay = schedule.Days[0]
+		}
+
+		scheduleDay.Entries = append(scheduleDay.Entries, entry)
+	}
+
+	for _, scheduleDay := range schedule.Days {
+		sort.Slice(scheduleDay.Entries, func(i, j int) bool {
+			a := scheduleDay.Entries[i]
+			b := scheduleDay.Entries[j]
+
+			if !a.Start.Equal(b.Start) {
+				return a.Start.Before(b.Start)
+			}
+
+			if a.Anime.ID != b.Anime.ID {
+				return a.Anime.ID < b.Anime.ID
+			}
+
+			return a.Episode.Number < b.Episode.Number
+		})
+	}
+
+	return schedule
+}
+
+// AiringSchedule returns the weekly schedule of the episodes in the calendar of the user.
+func (user *User) AiringSchedule() *AiringSchedule {
+	now := time.Now()
+	return NewAiringSchedule(user.CalendarEpisodes(user.Settings(), now), user.TimeZone(), now)
+}
+
+// Day returns the schedule day that contains the given time or nil if it's not in the schedule.
+func (schedule *AiringSchedule) Day(t time.Time) *AiringScheduleDay {
+	year, month, day := t.In(schedule.TimeZone).Date()
+
+	for _, scheduleDay := range schedule.Days {
+		y, m, d := scheduleDay.Date.Date()
+
+		if y == year && m == month && d == day {
+			return scheduleDay
+		}
+	}
+
+	return nil
+}
+
+// Weekday returns the local weekday.
+func (scheduleDay *AiringScheduleDay) Weekday() time.Weekday {
+	return scheduleDay.Date.Weekday()
+}
+
+// StartsPreviousDay tells you whether the episode started airing before the local day it's listed on.
+func (entry *AiringScheduleEntry) StartsPreviousDay(scheduleDay *AiringScheduleDay) bool {
+	return entry.Start.Before(scheduleDay.Date)
+}
+
+// EndsNextDay tells you whether the episode is still airing after local midnight.
+func (entry *AiringScheduleEntry) EndsNextDay() bool {
+	year, month, day := entry.Start.Date()
+	midnight := time.Date(year, month, day+1, 0, 0, 0, 0, entry.Start.Location())
+	return entry.End.After(midnight)
+}
+
+// StartTimeHuman returns the local start time in human readable form.
+func (entry *AiringScheduleEntry) StartTimeHuman() string {
+	return entry.Start.Format("15:04")
+}
+
+// EndTimeHuman returns the local end time in human readable form.
+func (entry *AiringScheduleEntry) EndTimeHuman() string {
+	return entry.End.Format("15:04")
+}
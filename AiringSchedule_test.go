@@ -0,0 +1,104 @@
+package arn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestScheduleEpisode(anime *arn.Anime, number int, start string) *arn.UpcomingEpisode {
+	return &arn.UpcomingEpisode{
+		Anime: anime,
+		Episode: &arn.AnimeEpisode{
+			Number:     number,
+			AiringDate: arn.AiringDate{Start: start},
+		},
+	}
+}
+
+func TestAiringScheduleDaylightSavingTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	anime := &arn.Anime{HasID: arn.HasID{ID: "steins-gate"}, EpisodeLength: 30}
+	episodes := []*arn.UpcomingEpisode{
+		// Sunday, after the clocks moved forward
+		newTestScheduleEpisode(anime, 6, "2019-03-31T10:30:00+09:00"),
+
+		// Sunday, before the clocks moved forward
+		newTestScheduleEpisode(anime, 5, "2019-03-31T09:30:00+09:00"),
+
+		// Friday 23:45, ends on Saturday
+		newTestScheduleEpisode(anime, 4, "2019-03-29T22:45:00Z"),
+
+		// Wednesday 23:50, still airing on Thursday
+		newTestScheduleEpisode(anime, 3, "2019-03-27T22:50:00Z"),
+
+		// Not in the schedule
+		newTestScheduleEpisode(anime, 2, "2019-03-27T12:00:00Z"),
+		newTestScheduleEpisode(anime, 7, "2019-04-04T12:00:00Z"),
+		newTestScheduleEpisode(anime, 8, "2019-04"),
+	}
+
+	now := time.Date(2019, 3, 28, 12, 0, 0, 0, time.UTC)
+	schedule := arn.NewAiringSchedule(episodes, berlin, now)
+	assert.Len(t, schedule.Days, 7)
+	assert.Equal(t, time.Thursday, schedule.Days[0].Weekday())
+
+	// Still airing at midnight
+	thursday := schedule.Days[0]
+	assert.Len(t, thursday.Entries, 1)
+	assert.Equal(t, 3, thursday.Entries[0].Episode.Number)
+	assert.True(t, thursday.Entries[0].StartsPreviousDay(thursday))
+
+	// Crossing midnight
+	friday := schedule.Days[1]
+	assert.Len(t, friday.Entries, 1)
+	assert.Equal(t, "23:45", friday.Entries[0].StartTimeHuman())
+	assert.Equal(t, "00:15", friday.Entries[0].EndTimeHuman())
+	assert.True(t, friday.Entries[0].EndsNextDay())
+	assert.Empty(t, schedule.Days[2].Entries)
+
+	// The day of the daylight saving time transition only has 23 hours
+	sunday := schedule.Days[3]
+	assert.Equal(t, time.Sunday, sunday.Weekday())
+	assert.Equal(t, 23*time.Hour, schedule.Days[4].Date.Sub(sunday.Date))
+	assert.Len(t, sunday.Entries, 2)
+	assert.Equal(t, "01:30", sunday.Entries[0].StartTimeHuman())
+	assert.Equal(t, "03:30", sunday.Entries[1].StartTimeHuman())
+	assert.False(t, sunday.Entries[1].EndsNextDay())
+
+	for _, scheduleDay := range schedule.Days[4:] {
+		assert.Empty(t, scheduleDay.Entries)
+	}
+}
+
+func TestAiringScheduleTimeZones(t *testing.T) {
+	anime := &arn.Anime{HasID: arn.HasID{ID: "made-in-abyss"}}
+	episodes := []*arn.UpcomingEpisode{
+		newTestScheduleEpisode(anime, 1, "2017-07-07T14:45:00Z"),
+	}
+
+	now := time.Date(2017, 7, 6, 12, 0, 0, 0, time.UTC)
+
+	// Friday night in Tokyo
+	tokyo, ok := arn.ParseTimeZone("Asia/Tokyo")
+	assert.True(t, ok)
+	schedule := arn.NewAiringSchedule(episodes, tokyo, now)
+	friday := schedule.Day(time.Date(2017, 7, 7, 0, 0, 0, 0, tokyo))
+	assert.NotNil(t, friday)
+	assert.Equal(t, time.Friday, friday.Weekday())
+	assert.Equal(t, "23:45", friday.Entries[0].StartTimeHuman())
+	assert.True(t, friday.Entries[0].EndsNextDay())
+
+	// Friday morning in New York, using a fixed offset
+	newYork, ok := arn.ParseTimeZone("-04:00")
+	assert.True(t, ok)
+	schedule = arn.NewAiringSchedule(episodes, newYork, now)
+	assert.Equal(t, time.Thursday, schedule.Days[0].Weekday())
+	assert.Len(t, schedule.Days[1].Entries, 1)
+	assert.Equal(t, "10:45", schedule.Days[1].Entries[0].StartTimeHuman())
+	assert.Equal(t, "11:15", schedule.Days[1].Entries[0].EndTimeHuman())
+}
@@ -1,6 +1,8 @@
 package arn
 
 import (
+	"time"
+
 	"github.com/animenotifier/anilist"
 	jsoniter "github.com/json-iterator/go"
 )
@@ -17,3 +19,39 @@ func (match *AniListMatch) JSON() string {
 	PanicOnError(err)
 	return string(b)
 }
+
+// ImportFields returns the anime list item fields that AniList entries supply.
+func (match *AniListMatch) ImportFields() []string {
+	return importFields
+}
+
+// AnimeListItem converts the AniList entry to an anime list item.
+// The raw AniList score uses a 0-100 scale.
+// Entries without an update date have no edit date so that they count as older than edited items.
+func (match *AniListMatch) AnimeListItem() *AnimeListItem {
+	now := time.Now().UTC()
+	created := now
+	edited := ""
+
+	if match.AniListItem.CreatedAt != 0 {
+		created = time.Unix(int64(match.AniListItem.CreatedAt), 0).UTC()
+	}
+
+	if match.AniListItem.UpdatedAt != 0 {
+		edited = time.Unix(int64(match.AniListItem.UpdatedAt), 0).UTC().Format(time.RFC3339)
+	}
+
+	return &AnimeListItem{
+		AnimeID:  match.ARNAnime.ID,
+		Status:   AniListAnimeListStatus(match.AniListItem),
+		Episodes: match.AniListItem.Progress,
+		Rating: AnimeListItemRating{
+			Overall: float64(match.AniListItem.ScoreRaw) / 10,
+		},
+		Notes:        match.AniListItem.Notes,
+		RewatchCount: match.AniListItem.Repeat,
+		Private:      match.AniListItem.Private,
+		Created:      created.Format(time.RFC3339),
+		Edited:       edited,
+	}
+}
@@ -20,6 +20,9 @@ import (
 // AnimeDateFormat describes the anime date format for the date conversion.
 const AnimeDateFormat = validate.DateFormat
 
+// DefaultEpisodeDuration is the assumed length of an episode if the episode length is unknown.
+const DefaultEpisodeDuration = 30 * time.Minute
+
 // AnimeSourceHumanReadable maps the anime source to a human readable version.
 var AnimeSourceHumanReadable = map[string]string{}
 
@@ -337,6 +340,15 @@ func (anime *Anime) StartDateTime() time.Time {
 	return t
 }
 
+// EpisodeDuration returns the length of a single episode.
+func (anime *Anime) EpisodeDuration() time.Duration {
+	if anime.EpisodeLength <= 0 {
+		return DefaultEpisodeDuration
+	}
+
+	return time.Duration(anime.EpisodeLength) * time.Minute
+}
+
 // EndDateTime returns the end date as a time object.
 func (anime *Anime) EndDateTime() time.Time {
 	format := AnimeDateFormat
@@ -394,24 +406,13 @@ func (anime *Anime) RefreshEpisodes() error {
 	// Save number of available episodes for comparison later
 	oldAvailableCount := episodes.AvailableCount()
 
-	// Shoboi
-	shoboiEpisodes, err := anime.ShoboiEpisodes()
+	// Query the episode providers
+	delays, errs := anime.UpdateEpisodes(episodes, EpisodeProviders, time.Now())
 
-	if err != nil {
+	for _, err := range errs {
 		color.Red(err.Error())
 	}
 
-	episodes.Merge(shoboiEpisodes)
-
-	// AnimeTwist
-	twistEpisodes, err := anime.TwistEpisodes()
-
-	if err != nil {
-		color.Red(err.Error())
-	}
-
-	episodes.Merge(twistEpisodes)
-
 	// Count number of available episodes
 	newAvailableCount := episodes.AvailableCount()
 
@@ -435,6 +436,37 @@ func (anime *Anime) RefreshEpisodes() error {
 		}()
 	}
 
+	// Notify all users who are watching the anime about schedule changes.
+	// Delays of several episodes are combined into one notification.
+	for _, delay := range delays {
+		delay.Save()
+	}
+
+	if len(delays) > 0 {
+		go func() {
+			for _, user := range anime.UsersWatchingOrPlanned() {
+				if !user.Settings().Notification.AnimeEpisodeDelays {
+					continue
+				}
+
+				user.SendNotification(EpisodeDelaysPushNotification(anime, delays, user))
+			}
+		}()
+	}
+
+	episodes.Save()
+
+	return nil
+}
+
+// UpdateEpisodes merges the episodes of all providers in the registry into the episode list,
+// numbers the episodes without number and predicts missing airing dates.
+// Returns the changes of confirmed airing dates of episodes that haven't aired at the given time
+// and the errors of the providers that failed. Neither the episodes nor the delays are saved.
+func (anime *Anime) UpdateEpisodes(episodes *AnimeEpisodes, registry *EpisodeProviderRegistry, now time.Time) ([]*EpisodeDelay, []error) {
+	oldAiringDates := episodes.AiringDates()
+	sources, errs := registry.Merge(anime, episodes)
+
 	// Number remaining episodes
 	startNumber := 0
 
@@ -448,43 +480,15 @@ func (anime *Anime) RefreshEpisodes() error {
 		episode.Number = startNumber
 	}
 
-	// Guess airing dates
-	oneWeek := 7 * 24 * time.Hour
-	lastAiringDate := ""
-	timeDifference := oneWeek
-
-	for _, episode := range episodes.Items {
-		if validate.DateTime(episode.AiringDate.Start) {
-			if lastAiringDate != "" {
-				a, _ := time.Parse(time.RFC3339, lastAiringDate)
-				b, _ := time.Parse(time.RFC3339, episode.AiringDate.Start)
-				timeDifference = b.Sub(a)
-
-				// Cap time difference at one week
-				if timeDifference > oneWeek {
-					timeDifference = oneWeek
-				}
-			}
-
-			lastAiringDate = episode.AiringDate.Start
-			continue
-		}
-
-		// Add 1 week to the last known airing date
-		nextAiringDate, _ := time.Parse(time.RFC3339, lastAiringDate)
-		nextAiringDate = nextAiringDate.Add(timeDifference)
-
-		// Guess start and end time
-		episode.AiringDate.Start = nextAiringDate.Format(time.RFC3339)
-		episode.AiringDate.End = nextAiringDate.Add(30 * time.Minute).Format(time.RFC3339)
+	episodes.Sort()
 
-		// Set this date as the new last known airing date
-		lastAiringDate = episode.AiringDate.Start
-	}
+	// Predict missing airing dates
+	NewAiringPredictor(episodes.Items).Apply(episodes.Items, anime.EpisodeDuration())
 
-	episodes.Save()
+	// Detect schedule changes
+	delays := DetectEpisodeDelays(anime.ID, oldAiringDates, episodes.Items, sources, now)
 
-	return nil
+	return delays, errs
 }
 
 // ShoboiEpisodes returns a slice of episode info from cal.syoboi.jp.
@@ -624,33 +628,45 @@ func (anime *Anime) EpisodeCountString() string {
 
 // ImportKitsuMapping imports the given Kitsu mapping.
 func (anime *Anime) ImportKitsuMapping(mapping *kitsu.Mapping) {
+	service, serviceID, known := KitsuMappingService(mapping)
+
+	if !known {
+		color.Yellow("Unknown mapping: %s %s", mapping.Attributes.ExternalSite, mapping.Attributes.ExternalID)
+		return
+	}
+
+	anime.SetMapping(service, serviceID)
+}
+
+// KitsuMappingService returns our service name and the external ID for the Kitsu mapping.
+// Known mappings that we ignore return an empty service name.
+func KitsuMappingService(mapping *kitsu.Mapping) (service string, serviceID string, known bool) {
+	externalID := mapping.Attributes.ExternalID
+
 	switch mapping.Attributes.ExternalSite {
 	case "myanimelist/anime":
-		anime.SetMapping("myanimelist/anime", mapping.Attributes.ExternalID)
+		return "myanimelist/anime", externalID, true
 	case "anidb":
-		anime.SetMapping("anidb/anime", mapping.Attributes.ExternalID)
+		return "anidb/anime", externalID, true
 	case "trakt":
-		anime.SetMapping("trakt/anime", mapping.Attributes.ExternalID)
+		return "trakt/anime", externalID, true
 	// case "hulu":
-	// 	anime.SetMapping("hulu/anime", mapping.Attributes.ExternalID)
+	// 	return "hulu/anime", externalID, true
 	case "anilist":
-		externalID := mapping.Attributes.ExternalID
-		externalID = strings.TrimPrefix(externalID, "anime/")
-
-		anime.SetMapping("anilist/anime", externalID)
+		return "anilist/anime", strings.TrimPrefix(externalID, "anime/"), true
 	case "thetvdb", "thetvdb/series":
-		externalID := mapping.Attributes.ExternalID
 		slashPos := strings.Index(externalID, "/")
 
 		if slashPos != -1 {
 			externalID = externalID[:slashPos]
 		}
 
-		anime.SetMapping("thetvdb/anime", externalID)
+		return "thetvdb/anime", externalID, true
 	case "thetvdb/season":
 		// Ignore
+		return "", "", true
 	default:
-		color.Yellow("Unknown mapping: %s %s", mapping.Attributes.ExternalSite, mapping.Attributes.ExternalID)
+		return "", "", false
 	}
 }
 
@@ -200,6 +200,7 @@ func (anime *Anime) Delete() error {
 
 	// Delete the actual anime
 	DB.Delete("Anime", anime.ID)
+	notifyDelete("Anime", anime.ID)
 
 	return nil
 }
@@ -207,4 +208,5 @@ func (anime *Anime) Delete() error {
 // Save saves the anime in the database.
 func (anime *Anime) Save() {
 	DB.Set("Anime", anime.ID, anime)
+	notifySave("Anime", anime)
 }
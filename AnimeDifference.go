@@ -0,0 +1,274 @@
+package arn
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/animenotifier/kitsu"
+	"github.com/animenotifier/mal"
+)
+
+// Data providers that anime can be compared with
+const (
+	DataProviderMyAnimeList = "mal"
+	DataProviderKitsu       = "kitsu"
+)
+
+// Types of anime differences
+const (
+	AnimeDifferenceCanonicalTitle = "CanonicalTitle"
+	AnimeDifferenceEnglishTitle   = "EnglishTitle"
+	AnimeDifferenceJapaneseTitle  = "JapaneseTitle"
+	AnimeDifferenceSynopsis       = "Synopsis"
+	AnimeDifferenceEpisodeCount   = "EpisodeCount"
+	AnimeDifferenceStartDate      = "StartDate"
+	AnimeDifferenceEndDate        = "EndDate"
+	AnimeDifferenceGenres         = "Genres"
+	AnimeDifferenceStatus         = "Status"
+	AnimeDifferenceStudios        = "Studios"
+)
+
+// AnimeDifference is a field where our anime data differs from an external data provider.
+// The values are formatted as strings, lists are sorted and joined by ", ".
+type AnimeDifference struct {
+	AnimeID      string `json:"animeId"`
+	DataProvider string `json:"dataProvider"`
+	ServiceID    string `json:"serviceId"`
+	TypeName     string `json:"typeName"`
+	Local        string `json:"local"`
+	Remote       string `json:"remote"`
+}
+
+// ID returns the ID that is used to ignore the difference.
+func (difference *AnimeDifference) ID() string {
+	return CreateDifferenceID(difference.AnimeID, difference.DataProvider, difference.ServiceID, difference.TypeName)
+}
+
+// Hash returns the hash of the value suggested by the data provider.
+// Ignored differences are only ignored as long as the suggested value stays the same.
+func (difference *AnimeDifference) Hash() uint64 {
+	h := fnv.New64()
+	h.Write([]byte(difference.Remote))
+	return h.Sum64()
+}
+
+// IsIgnored tells you whether an editor decided to ignore the difference.
+func (difference *AnimeDifference) IsIgnored() bool {
+	return IsAnimeDifferenceIgnored(difference.AnimeID, difference.DataProvider, difference.ServiceID, difference.TypeName, difference.Hash())
+}
+
+// Explanation returns a short human readable description of the difference.
+func (difference *AnimeDifference) Explanation() string {
+	switch difference.TypeName {
+	case AnimeDifferenceCanonicalTitle:
+		return "Canonical titles are different"
+	case AnimeDifferenceEnglishTitle:
+		return "English titles are different"
+	case AnimeDifferenceJapaneseTitle:
+		return "Japanese titles are different"
+	case AnimeDifferenceSynopsis:
+		return "Synopsis is different"
+	case AnimeDifferenceEpisodeCount:
+		return "Episode counts are different"
+	case AnimeDifferenceStartDate:
+		return "Start dates are different"
+	case AnimeDifferenceEndDate:
+		return "End dates are different"
+	case AnimeDifferenceGenres:
+		return "Genres are different"
+	case AnimeDifferenceStatus:
+		return "Status is different"
+	case AnimeDifferenceStudios:
+		return "Studios are different"
+	default:
+		return difference.TypeName + " is different"
+	}
+}
+
+// String implements the default string serialization.
+func (difference *AnimeDifference) String() string {
+	return fmt.Sprintf("%s: %q (arn) vs. %q (%s)", difference.TypeName, difference.Local, difference.Remote, difference.DataProvider)
+}
+
+// DiffMyAnimeListAnime compares the anime with its MyAnimeList data.
+// The studios need to be the companies referenced by the anime's studio IDs.
+func DiffMyAnimeListAnime(anime *Anime, malAnime *mal.Anime, studios []*Company) []*AnimeDifference {
+	differ := &animeDiffer{
+		anime:        anime,
+		dataProvider: DataProviderMyAnimeList,
+		serviceID:    malAnime.ID,
+	}
+
+	localStudios := make([]string, 0, len(studios))
+	remoteStudios := make([]string, 0, len(malAnime.Studios))
+
+	for _, studio := range studios {
+		localStudios = append(localStudios, studio.Name.English)
+	}
+
+	for _, studio := range malAnime.Studios {
+		remoteStudios = append(remoteStudios, studio.Name)
+	}
+
+	differ.compareTitles(malAnime.Title, malAnime.EnglishTitle, malAnime.JapaneseTitle)
+	differ.compare(AnimeDifferenceSynopsis, anime.Summary, FixAnimeDescription(malAnime.Synopsis))
+	differ.compareEpisodeCount(malAnime.EpisodeCount)
+	differ.compareDate(AnimeDifferenceStartDate, anime.StartDate, malAnime.StartDate)
+	differ.compareDate(AnimeDifferenceEndDate, anime.EndDate, malAnime.EndDate)
+	differ.compareStatus(malAnime.Status)
+	differ.compareList(AnimeDifferenceGenres, anime.Genres, malAnime.Genres)
+	differ.compareList(AnimeDifferenceStudios, localStudios, remoteStudios)
+
+	return differ.differences
+}
+
+// DiffKitsuAnime compares the anime with its Kitsu data.
+// Kitsu anime don't contain genres and studios.
+func DiffKitsuAnime(anime *Anime, kitsuAnime *kitsu.Anime) []*AnimeDifference {
+	attributes := &kitsuAnime.Attributes
+	differ := &animeDiffer{
+		anime:        anime,
+		dataProvider: DataProviderKitsu,
+		serviceID:    kitsuAnime.ID,
+	}
+
+	differ.compareTitles(attributes.CanonicalTitle, attributes.Titles.En, attributes.Titles.JaJp)
+	differ.compare(AnimeDifferenceSynopsis, anime.Summary, FixAnimeDescription(attributes.Synopsis))
+	differ.compareEpisodeCount(attributes.EpisodeCount)
+	differ.compareDate(AnimeDifferenceStartDate, anime.StartDate, attributes.StartDate)
+	differ.compareDate(AnimeDifferenceEndDate, anime.EndDate, attributes.EndDate)
+	differ.compareStatus(attributes.Status)
+
+	return differ.differences
+}
+
+// Differences compares the anime with its MyAnimeList and Kitsu data
+// and returns all differences that haven't been ignored by an editor.
+func (anime *Anime) Differences() []*AnimeDifference {
+	var differences []*AnimeDifference
+	malID := anime.GetMapping("myanimelist/anime")
+
+	if malID != "" {
+		obj, err := MAL.Get("Anime", malID)
+
+		if err == nil {
+			differences = append(differences, DiffMyAnimeListAnime(anime, obj.(*mal.Anime), anime.Studios())...)
+		}
+	}
+
+	kitsuID := anime.GetMapping("kitsu/anime")
+
+	if kitsuID != "" {
+		obj, err := Kitsu.Get("Anime", kitsuID)
+
+		if err == nil {
+			differences = append(differences, DiffKitsuAnime(anime, obj.(*kitsu.Anime))...)
+		}
+	}
+
+	notIgnored := differences[:0]
+
+	for _, difference := range differences {
+		if !difference.IsIgnored() {
+			notIgnored = append(notIgnored, difference)
+		}
+	}
+
+	return notIgnored
+}
+
+// animeDiffer collects the differences between an anime and a single data provider.
+// Empty remote values are never reported because there is nothing to suggest.
+type animeDiffer struct {
+	anime        *Anime
+	dataProvider string
+	serviceID    string
+	differences  []*AnimeDifference
+}
+
+// compare adds a difference if the values are not equal.
+func (differ *animeDiffer) compare(typeName string, local string, remote string) {
+	if remote == "" || local == remote {
+		return
+	}
+
+	differ.differences = append(differ.differences, &AnimeDifference{
+		AnimeID:      differ.anime.ID,
+		DataProvider: differ.dataProvider,
+		ServiceID:    differ.serviceID,
+		TypeName:     typeName,
+		Local:        local,
+		Remote:       remote,
+	})
+}
+
+// compareTitles compares the canonical, English and Japanese titles.
+func (differ *animeDiffer) compareTitles(canonical string, english string, japanese string) {
+	title := differ.anime.Title
+
+	if title == nil {
+		title = &AnimeTitle{}
+	}
+
+	differ.compare(AnimeDifferenceCanonicalTitle, title.Canonical, canonical)
+	differ.compare(AnimeDifferenceEnglishTitle, title.English, english)
+	differ.compare(AnimeDifferenceJapaneseTitle, title.Japanese, japanese)
+}
+
+// compareEpisodeCount compares the episode counts, 0 means unknown.
+func (differ *animeDiffer) compareEpisodeCount(remote int) {
+	if remote == 0 {
+		return
+	}
+
+	differ.compare(AnimeDifferenceEpisodeCount, strconv.Itoa(differ.anime.EpisodeCount), strconv.Itoa(remote))
+}
+
+// compareDate compares the date parts of two dates.
+func (differ *animeDiffer) compareDate(typeName string, local string, remote string) {
+	if len(remote) > len(AnimeDateFormat) {
+		remote = remote[:len(AnimeDateFormat)]
+	}
+
+	if len(local) > len(AnimeDateFormat) {
+		local = local[:len(AnimeDateFormat)]
+	}
+
+	differ.compare(typeName, local, remote)
+}
+
+// compareStatus compares the airing status.
+// "tba" and Kitsu's "unreleased" mean the same as "upcoming".
+func (differ *animeDiffer) compareStatus(remote string) {
+	if normalizeAnimeStatus(differ.anime.Status) == normalizeAnimeStatus(remote) {
+		return
+	}
+
+	differ.compare(AnimeDifferenceStatus, differ.anime.Status, remote)
+}
+
+// compareList compares two lists regardless of their order.
+func (differ *animeDiffer) compareList(typeName string, local []string, remote []string) {
+	differ.compare(typeName, joinSorted(local), joinSorted(remote))
+}
+
+// normalizeAnimeStatus returns the status with equivalent values unified.
+func normalizeAnimeStatus(status string) string {
+	switch status {
+	case "tba", "unreleased":
+		return "upcoming"
+	default:
+		return status
+	}
+}
+
+// joinSorted returns a sorted copy of the list joined by ", ".
+func joinSorted(list []string) string {
+	sorted := make([]string, len(list))
+	copy(sorted, list)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
@@ -0,0 +1,105 @@
+package arn_test
+
+import (
+	"hash/fnv"
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/animenotifier/kitsu"
+	"github.com/animenotifier/mal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDiffAnime() *arn.Anime {
+	anime := &arn.Anime{
+		HasID:        arn.HasID{ID: "steins-gate"},
+		Title:        &arn.AnimeTitle{Canonical: "Steins;Gate", English: "Steins;Gate", Japanese: "シュタインズ・ゲート"},
+		Summary:      "Eccentric scientist Rintarou Okabe has a never-ending thirst for scientific exploration.",
+		EpisodeCount: 24,
+		StartDate:    "2011-04-06",
+		EndDate:      "2011-09-14",
+		Status:       "finished",
+		Genres:       []string{"Thriller", "Sci-Fi"},
+	}
+
+	return anime
+}
+
+func findDifference(differences []*arn.AnimeDifference, typeName string) *arn.AnimeDifference {
+	for _, difference := range differences {
+		if difference.TypeName == typeName {
+			return difference
+		}
+	}
+
+	return nil
+}
+
+func TestDiffMyAnimeListAnime(t *testing.T) {
+	anime := newTestDiffAnime()
+	studios := []*arn.Company{{Name: arn.CompanyName{English: "White Fox"}}}
+	malAnime := &mal.Anime{
+		ID:            "9253",
+		Title:         "Steins;Gate",
+		EnglishTitle:  "Steins;Gate",
+		JapaneseTitle: "シュタインズ・ゲート",
+		Synopsis:      anime.Summary + "\n\n[Written by MAL Rewrite]",
+		EpisodeCount:  25,
+		StartDate:     "2011-04-06",
+		EndDate:       "2011-09-14",
+		Status:        "finished",
+		Genres:        []string{"Sci-Fi", "Thriller"},
+		Studios:       []*mal.Producer{{ID: "314", Name: "White Fox"}, {ID: "1", Name: "Nitroplus"}},
+	}
+
+	differences := arn.DiffMyAnimeListAnime(anime, malAnime, studios)
+	assert.Len(t, differences, 2)
+
+	episodes := findDifference(differences, arn.AnimeDifferenceEpisodeCount)
+	assert.NotNil(t, episodes)
+	assert.Equal(t, "24", episodes.Local)
+	assert.Equal(t, "25", episodes.Remote)
+	assert.Equal(t, "arn:steins-gate|mal:9253|EpisodeCount", episodes.ID())
+
+	studioDifference := findDifference(differences, arn.AnimeDifferenceStudios)
+	assert.NotNil(t, studioDifference)
+	assert.Equal(t, "White Fox", studioDifference.Local)
+	assert.Equal(t, "Nitroplus, White Fox", studioDifference.Remote)
+}
+
+func TestDiffKitsuAnime(t *testing.T) {
+	anime := newTestDiffAnime()
+	anime.Status = "upcoming"
+	anime.EndDate = ""
+
+	kitsuAnime := &kitsu.Anime{ID: "5646"}
+	kitsuAnime.Attributes.CanonicalTitle = "Steins;Gate"
+	kitsuAnime.Attributes.Titles.JaJp = "STEINS;GATE"
+	kitsuAnime.Attributes.StartDate = "2011-04-06"
+	kitsuAnime.Attributes.EndDate = "2011-09-14"
+	kitsuAnime.Attributes.Status = "unreleased"
+
+	differences := arn.DiffKitsuAnime(anime, kitsuAnime)
+	assert.Len(t, differences, 2)
+
+	// "unreleased" is the same as "upcoming" and empty Kitsu values are not reported
+	assert.Nil(t, findDifference(differences, arn.AnimeDifferenceStatus))
+	assert.Nil(t, findDifference(differences, arn.AnimeDifferenceEpisodeCount))
+	assert.Nil(t, findDifference(differences, arn.AnimeDifferenceEnglishTitle))
+
+	assert.NotNil(t, findDifference(differences, arn.AnimeDifferenceJapaneseTitle))
+
+	endDate := findDifference(differences, arn.AnimeDifferenceEndDate)
+	assert.NotNil(t, endDate)
+	assert.Equal(t, arn.DataProviderKitsu, endDate.DataProvider)
+	assert.Equal(t, "2011-09-14", endDate.Remote)
+}
+
+func TestAnimeDifferenceHash(t *testing.T) {
+	difference := &arn.AnimeDifference{Remote: "25"}
+	h := fnv.New64()
+	h.Write([]byte("25"))
+
+	assert.Equal(t, h.Sum64(), difference.Hash())
+	assert.NotEqual(t, (&arn.AnimeDifference{Remote: "26"}).Hash(), difference.Hash())
+}
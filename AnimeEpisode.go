@@ -2,6 +2,20 @@ package arn
 
 import "github.com/animenotifier/arn/validate"
 
+// Episode fields that can be merged from other sources
+const (
+	EpisodeFieldTitle      = "title"
+	EpisodeFieldAiringDate = "airingDate"
+	EpisodeFieldLinks      = "links"
+)
+
+// EpisodeFields is the list of all episode fields that can be merged.
+var EpisodeFields = []string{
+	EpisodeFieldTitle,
+	EpisodeFieldAiringDate,
+	EpisodeFieldLinks,
+}
+
 // AnimeEpisode ...
 type AnimeEpisode struct {
 	Number     int               `json:"number" editable:"true"`
@@ -17,6 +31,29 @@ type EpisodeTitle struct {
 	Japanese string `json:"japanese" editable:"true"`
 }
 
+// ByLanguage returns the episode title in the given title language.
+// Falls back to the other languages if there is no title in that language.
+func (title *EpisodeTitle) ByLanguage(language string) string {
+	var titles []string
+
+	switch language {
+	case TitleLanguageEnglish:
+		titles = []string{title.English, title.Romaji, title.Japanese}
+	case TitleLanguageJapanese:
+		titles = []string{title.Japanese, title.Romaji, title.English}
+	default:
+		titles = []string{title.Romaji, title.English, title.Japanese}
+	}
+
+	for _, t := range titles {
+		if t != "" {
+			return t
+		}
+	}
+
+	return ""
+}
+
 // Available tells you whether the episode is available (triggered when it has a link).
 func (a *AnimeEpisode) Available() bool {
 	return len(a.Links) > 0
@@ -29,41 +66,56 @@ func (a *AnimeEpisode) AvailableOn(serviceName string) bool {
 
 // Merge combines the data of both episodes to one.
 func (a *AnimeEpisode) Merge(b *AnimeEpisode) {
+	a.MergeFields(b, EpisodeFields...)
+}
+
+// MergeFields combines the given fields of both episodes to one.
+// Empty values in b never overwrite the values in a, including unknown episode numbers.
+func (a *AnimeEpisode) MergeFields(b *AnimeEpisode, fields ...string) {
 	if b == nil {
 		return
 	}
 
-	a.Number = b.Number
-
-	// Titles
-	if b.Title.Romaji != "" {
-		a.Title.Romaji = b.Title.Romaji
+	if b.Number != -1 {
+		a.Number = b.Number
 	}
 
-	if b.Title.English != "" {
-		a.Title.English = b.Title.English
-	}
+	for _, field := range fields {
+		switch field {
+		case EpisodeFieldTitle:
+			if b.Title.Romaji != "" {
+				a.Title.Romaji = b.Title.Romaji
+			}
 
-	if b.Title.Japanese != "" {
-		a.Title.Japanese = b.Title.Japanese
-	}
+			if b.Title.English != "" {
+				a.Title.English = b.Title.English
+			}
 
-	// Airing date
-	if validate.DateTime(b.AiringDate.Start) {
-		a.AiringDate.Start = b.AiringDate.Start
-	}
+			if b.Title.Japanese != "" {
+				a.Title.Japanese = b.Title.Japanese
+			}
 
-	if validate.DateTime(b.AiringDate.End) {
-		a.AiringDate.End = b.AiringDate.End
-	}
+		case EpisodeFieldAiringDate:
+			if validate.DateTime(b.AiringDate.Start) {
+				a.AiringDate.Start = b.AiringDate.Start
+				a.AiringDate.Estimated = b.AiringDate.Estimated
+				a.AiringDate.Confidence = b.AiringDate.Confidence
+				a.AiringDate.Source = b.AiringDate.Source
+			}
 
-	// Links
-	if a.Links == nil {
-		a.Links = map[string]string{}
-	}
+			if validate.DateTime(b.AiringDate.End) {
+				a.AiringDate.End = b.AiringDate.End
+			}
+
+		case EpisodeFieldLinks:
+			if a.Links == nil {
+				a.Links = map[string]string{}
+			}
 
-	for name, link := range b.Links {
-		a.Links[name] = link
+			for name, link := range b.Links {
+				a.Links[name] = link
+			}
+		}
 	}
 }
 
@@ -49,6 +49,13 @@ func (episodes *AnimeEpisodes) Find(episodeNumber int) (*AnimeEpisode, int) {
 
 // Merge combines the data of both episode slices to one.
 func (episodes *AnimeEpisodes) Merge(b []*AnimeEpisode) {
+	episodes.MergeFields(b, EpisodeFields...)
+}
+
+// MergeFields combines the given fields of both episode slices to one.
+// Episodes are matched by their number, unknown episodes are added.
+// Episodes without a number are matched by their position in the slice.
+func (episodes *AnimeEpisodes) MergeFields(b []*AnimeEpisode, fields ...string) {
 	if b == nil {
 		return
 	}
@@ -57,14 +64,44 @@ func (episodes *AnimeEpisodes) Merge(b []*AnimeEpisode) {
 	defer episodes.Unlock()
 
 	for index, episode := range b {
-		if index >= len(episodes.Items) {
-			episodes.Items = append(episodes.Items, episode)
+		var existing *AnimeEpisode
+
+		if episode.Number == -1 {
+			if index < len(episodes.Items) {
+				existing = episodes.Items[index]
+			}
 		} else {
-			episodes.Items[index].Merge(episode)
+			for _, item := range episodes.Items {
+				if item.Number == episode.Number {
+					existing = item
+					break
+				}
+			}
+		}
+
+		if existing == nil {
+			existing = NewAnimeEpisode()
+			episodes.Items = append(episodes.Items, existing)
 		}
+
+		existing.MergeFields(episode, fields...)
 	}
 }
 
+// AiringDates returns a copy of the airing dates mapped by episode number.
+func (episodes *AnimeEpisodes) AiringDates() map[int]AiringDate {
+	episodes.Lock()
+	defer episodes.Unlock()
+
+	airingDates := make(map[int]AiringDate, len(episodes.Items))
+
+	for _, episode := range episodes.Items {
+		airingDates[episode.Number] = episode.AiringDate
+	}
+
+	return airingDates
+}
+
 // Last returns the last n items.
 func (episodes *AnimeEpisodes) Last(count int) []*AnimeEpisode {
 	return episodes.Items[len(episodes.Items)-count:]
@@ -28,8 +28,29 @@ func (episodes *AnimeEpisodes) Authorize(ctx *aero.Context, action string) error
 }
 
 // Edit creates an edit log entry.
+// Airing dates entered by editors are marked with the editor source
+// so that they're treated as confirmed and never replaced by estimates.
 func (episodes *AnimeEpisodes) Edit(ctx *aero.Context, key string, value reflect.Value, newValue reflect.Value) (consumed bool, err error) {
-	return edit(episodes, ctx, key, value, newValue)
+	consumed, err = edit(episodes, ctx, key, value, newValue)
+
+	if consumed || err != nil {
+		return consumed, err
+	}
+
+	var index int
+	var field string
+	_, scanErr := fmt.Sscanf(key, "Items[%d].AiringDate.%s", &index, &field)
+
+	if scanErr != nil || (field != "Start" && field != "End") || index < 0 || index >= len(episodes.Items) {
+		return false, nil
+	}
+
+	value.Set(newValue)
+	airing := &episodes.Items[index].AiringDate
+	airing.Source = AiringDateSourceEditor
+	airing.Estimated = false
+	airing.Confidence = 0
+	return true, nil
 }
 
 // OnAppend saves a log entry.
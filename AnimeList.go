@@ -104,47 +104,31 @@ func (list *AnimeList) Find(animeID string) *AnimeListItem {
 // Import adds an anime to the list if it hasn't been added yet
 // and if it did exist it will update episode, rating and notes.
 func (list *AnimeList) Import(item *AnimeListItem) {
+	list.ImportWithPolicy(item, &MergeImportPolicy{})
+}
+
+// ImportWithPolicy adds an anime to the list if it hasn't been added yet
+// and if it did exist the policy decides which fields are updated.
+func (list *AnimeList) ImportWithPolicy(item *AnimeListItem, policy ImportPolicy) {
 	existing := list.Find(item.AnimeID)
 
 	// If it doesn't exist yet: Simply add it.
 	if existing == nil {
+		if item.Edited == "" {
+			item.Edited = DateTimeUTC()
+		}
+
 		list.Lock()
 		list.Items = append(list.Items, item)
 		list.Unlock()
-		return
-	}
-
-	// Temporary save it before changing the status
-	// because status changes can modify the episode count.
-	// This will prevent loss of "episodes watched" data.
-	existingEpisodes := existing.Episodes
-
-	// Status
-	existing.Status = item.Status
-	existing.OnStatusChange()
 
-	// Episodes
-	if item.Episodes > existingEpisodes {
-		existing.Episodes = item.Episodes
-	} else {
-		existing.Episodes = existingEpisodes
-	}
-
-	existing.OnEpisodesChange()
-
-	// Rating
-	if existing.Rating.Overall == 0 {
-		existing.Rating.Overall = item.Rating.Overall
-		existing.Rating.Clamp()
-	}
-
-	if existing.Notes == "" {
-		existing.Notes = item.Notes
+		logWatchProgress(list.UserID, &AnimeListItem{AnimeID: item.AnimeID}, item)
+		return
 	}
 
-	if item.RewatchCount > existing.RewatchCount {
-		existing.RewatchCount = item.RewatchCount
-	}
+	previous := *existing
+	existing.merge(item, existing.Anime(), policy)
+	logWatchProgress(list.UserID, &previous, existing)
 
 	// Edited
 	existing.Edited = DateTimeUTC()
@@ -0,0 +1,341 @@
+package arn
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"math"
+	"strconv"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// myAnimeListStatus maps our list status to the status names used in MAL exports.
+var myAnimeListStatus = map[string]string{
+	AnimeListStatusWatching:  "Watching",
+	AnimeListStatusCompleted: "Completed",
+	AnimeListStatusHold:      "On-Hold",
+	AnimeListStatusDropped:   "Dropped",
+	AnimeListStatusPlanned:   "Plan to Watch",
+}
+
+// csvExportHeader is the first row of the CSV export.
+var csvExportHeader = []string{
+	"id",
+	"title",
+	"myanimelist",
+	"anilist",
+	"kitsu",
+	"status",
+	"episodes",
+	"rating",
+	"story",
+	"visuals",
+	"soundtrack",
+	"rewatchCount",
+	"private",
+	"notes",
+}
+
+// AnimeListExport converts an anime list to formats that can be imported by other services.
+type AnimeListExport struct {
+	UserID   string
+	UserName string
+	Items    []*AnimeListItem
+	Anime    map[string]*Anime
+}
+
+// AnimeListExportItem is a single entry of the JSON export.
+type AnimeListExportItem struct {
+	AnimeID      string                   `json:"animeId"`
+	Title        string                   `json:"title"`
+	Mappings     *AnimeListExportMappings `json:"mappings"`
+	Status       string                   `json:"status"`
+	Episodes     int                      `json:"episodes"`
+	Rating       float64                  `json:"rating"`
+	Score100     int                      `json:"score100"`
+	Notes        string                   `json:"notes"`
+	RewatchCount int                      `json:"rewatchCount"`
+	Private      bool                     `json:"private"`
+}
+
+// AnimeListExportMappings contains the IDs of the anime on services that support list imports.
+type AnimeListExportMappings struct {
+	MyAnimeList string `json:"myanimelist"`
+	AniList     string `json:"anilist"`
+	Kitsu       string `json:"kitsu"`
+}
+
+// NewAnimeListExport creates an export of the user's complete anime list.
+func NewAnimeListExport(user *User) (*AnimeListExport, error) {
+	list, err := GetAnimeList(user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list.Lock()
+	items := make([]*AnimeListItem, len(list.Items))
+	copy(items, list.Items)
+	list.Unlock()
+
+	animeIDs := make([]string, len(items))
+
+	for index, item := range items {
+		animeIDs[index] = item.AnimeID
+	}
+
+	animes := map[string]*Anime{}
+
+	for _, obj := range DB.GetMany("Anime", animeIDs) {
+		if obj != nil {
+			anime := obj.(*Anime)
+			animes[anime.ID] = anime
+		}
+	}
+
+	return &AnimeListExport{
+		UserID:   user.ID,
+		UserName: user.Nick,
+		Items:    items,
+		Anime:    animes,
+	}, nil
+}
+
+// Unmapped returns the items whose anime doesn't have a mapping for the given service,
+// e.g. "myanimelist/anime". These items are missing in exports for that service.
+func (export *AnimeListExport) Unmapped(serviceName string) []*AnimeListItem {
+	var unmapped []*AnimeListItem
+
+	for _, item := range export.Items {
+		anime := export.Anime[item.AnimeID]
+
+		if anime == nil || anime.GetMapping(serviceName) == "" {
+			unmapped = append(unmapped, item)
+		}
+	}
+
+	return unmapped
+}
+
+// MyAnimeListXML returns the list in the XML format of MyAnimeList exports.
+// Anime without a MyAnimeList mapping are skipped, see Unmapped.
+func (export *AnimeListExport) MyAnimeListXML() ([]byte, error) {
+	document := &malExport{}
+	document.Info.UserName = export.UserName
+	document.Info.ExportType = 1
+
+	for _, item := range export.Items {
+		anime := export.Anime[item.AnimeID]
+
+		if anime == nil {
+			continue
+		}
+
+		malID, err := strconv.Atoi(anime.GetMapping("myanimelist/anime"))
+
+		if err != nil {
+			continue
+		}
+
+		document.Anime = append(document.Anime, &malExportAnime{
+			ID:              malID,
+			Title:           malCDATA{anime.Title.Canonical},
+			Type:            dataListLabel("anime-types", anime.Type),
+			Episodes:        anime.EpisodeCount,
+			WatchedEpisodes: item.Episodes,
+			StartDate:       "0000-00-00",
+			FinishDate:      "0000-00-00",
+			Score:           ScaleRating(item.Rating.Overall, 10),
+			Status:          myAnimeListStatus[item.Status],
+			Comments:        malCDATA{item.Notes},
+			TimesWatched:    item.RewatchCount,
+			Tags:            malCDATA{},
+			UpdateOnImport:  1,
+		})
+
+		document.Info.Total++
+
+		switch item.Status {
+		case AnimeListStatusWatching:
+			document.Info.Watching++
+		case AnimeListStatusCompleted:
+			document.Info.Completed++
+		case AnimeListStatusHold:
+			document.Info.OnHold++
+		case AnimeListStatusDropped:
+			document.Info.Dropped++
+		case AnimeListStatusPlanned:
+			document.Info.Planned++
+		}
+	}
+
+	data, err := xml.MarshalIndent(document, "", "\t")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// CSV returns the list as comma separated values including a header row.
+func (export *AnimeListExport) CSV() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	writer := csv.NewWriter(&buffer)
+	err := writer.Write(csvExportHeader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range export.Items {
+		title := ""
+		mappings := &AnimeListExportMappings{}
+		anime := export.Anime[item.AnimeID]
+
+		if anime != nil {
+			title = anime.Title.Canonical
+			mappings = exportMappings(anime)
+		}
+
+		err := writer.Write([]string{
+			item.AnimeID,
+			title,
+			mappings.MyAnimeList,
+			mappings.AniList,
+			mappings.Kitsu,
+			item.Status,
+			strconv.Itoa(item.Episodes),
+			formatExportRating(item.Rating.Overall),
+			formatExportRating(item.Rating.Story),
+			formatExportRating(item.Rating.Visuals),
+			formatExportRating(item.Rating.Soundtrack),
+			strconv.Itoa(item.RewatchCount),
+			strconv.FormatBool(item.Private),
+			item.Notes,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// JSON returns the list including the IDs of AniList, Kitsu and MyAnimeList.
+// The score100 field contains the rating on the 100 point scale used by AniList.
+func (export *AnimeListExport) JSON() ([]byte, error) {
+	items := make([]*AnimeListExportItem, 0, len(export.Items))
+
+	for _, item := range export.Items {
+		exportItem := &AnimeListExportItem{
+			AnimeID:      item.AnimeID,
+			Mappings:     &AnimeListExportMappings{},
+			Status:       item.Status,
+			Episodes:     item.Episodes,
+			Rating:       item.Rating.Overall,
+			Score100:     ScaleRating(item.Rating.Overall, 100),
+			Notes:        item.Notes,
+			RewatchCount: item.RewatchCount,
+			Private:      item.Private,
+		}
+
+		anime := export.Anime[item.AnimeID]
+
+		if anime != nil {
+			exportItem.Title = anime.Title.Canonical
+			exportItem.Mappings = exportMappings(anime)
+		}
+
+		items = append(items, exportItem)
+	}
+
+	// jsoniter doesn't support tabs in MarshalIndent.
+	return jsoniter.MarshalIndent(items, "", "    ")
+}
+
+// ScaleRating converts a rating from our 0-10 scale to an integer scale with the given maximum.
+// Rated anime get at least 1 point because 0 means "not rated" on all services.
+func ScaleRating(rating float64, max int) int {
+	if rating <= 0 {
+		return 0
+	}
+
+	scaled := int(math.Round(rating / MaxRating * float64(max)))
+
+	if scaled < 1 {
+		return 1
+	}
+
+	if scaled > max {
+		return max
+	}
+
+	return scaled
+}
+
+// exportMappings returns the IDs of the anime on other services that support list imports.
+func exportMappings(anime *Anime) *AnimeListExportMappings {
+	return &AnimeListExportMappings{
+		MyAnimeList: anime.GetMapping("myanimelist/anime"),
+		AniList:     anime.GetMapping("anilist/anime"),
+		Kitsu:       anime.GetMapping("kitsu/anime"),
+	}
+}
+
+// formatExportRating formats a rating with at most one decimal.
+func formatExportRating(rating float64) string {
+	return strconv.FormatFloat(math.Round(rating*10)/10, 'f', -1, 64)
+}
+
+// dataListLabel returns the label for a value in the data list.
+func dataListLabel(dataList string, value string) string {
+	for _, option := range DataLists[dataList] {
+		if option.Value == value {
+			return option.Label
+		}
+	}
+
+	return value
+}
+
+// malExport is the root element of a MyAnimeList export.
+type malExport struct {
+	XMLName xml.Name `xml:"myanimelist"`
+	Info    struct {
+		UserName   string `xml:"user_name"`
+		ExportType int    `xml:"user_export_type"`
+		Total      int    `xml:"user_total_anime"`
+		Watching   int    `xml:"user_total_watching"`
+		Completed  int    `xml:"user_total_completed"`
+		OnHold     int    `xml:"user_total_onhold"`
+		Dropped    int    `xml:"user_total_dropped"`
+		Planned    int    `xml:"user_total_plantowatch"`
+	} `xml:"myinfo"`
+	Anime []*malExportAnime `xml:"anime"`
+}
+
+// malExportAnime is a single anime in a MyAnimeList export.
+type malExportAnime struct {
+	ID              int      `xml:"series_animedb_id"`
+	Title           malCDATA `xml:"series_title"`
+	Type            string   `xml:"series_type"`
+	Episodes        int      `xml:"series_episodes"`
+	WatchedEpisodes int      `xml:"my_watched_episodes"`
+	StartDate       string   `xml:"my_start_date"`
+	FinishDate      string   `xml:"my_finish_date"`
+	Score           int      `xml:"my_score"`
+	Status          string   `xml:"my_status"`
+	Comments        malCDATA `xml:"my_comments"`
+	TimesWatched    int      `xml:"my_times_watched"`
+	Tags            malCDATA `xml:"my_tags"`
+	UpdateOnImport  int      `xml:"update_on_import"`
+}
+
+// malCDATA is a text that is written as a CDATA section.
+type malCDATA struct {
+	Text string `xml:",cdata"`
+}
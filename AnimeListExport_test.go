@@ -0,0 +1,100 @@
+package arn_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestExport() *arn.AnimeListExport {
+	steinsGate := &arn.Anime{HasID: arn.HasID{ID: "steins-gate"}, Type: "tv", EpisodeCount: 24}
+	steinsGate.Title = &arn.AnimeTitle{Canonical: "Steins;Gate"}
+	steinsGate.SetMapping("myanimelist/anime", "9253")
+	steinsGate.SetMapping("anilist/anime", "9253")
+	steinsGate.SetMapping("kitsu/anime", "5646")
+
+	unmapped := &arn.Anime{HasID: arn.HasID{ID: "unmapped"}, Type: "movie", EpisodeCount: 1}
+	unmapped.Title = &arn.AnimeTitle{Canonical: "Unmapped"}
+
+	completed := &arn.AnimeListItem{
+		AnimeID:      steinsGate.ID,
+		Status:       arn.AnimeListStatusCompleted,
+		Episodes:     24,
+		Notes:        "El Psy <Kongroo>",
+		RewatchCount: 2,
+	}
+
+	completed.Rating.Overall = 9.44
+
+	planned := &arn.AnimeListItem{
+		AnimeID: unmapped.ID,
+		Status:  arn.AnimeListStatusPlanned,
+	}
+
+	return &arn.AnimeListExport{
+		UserName: "Okabe",
+		Items:    []*arn.AnimeListItem{completed, planned},
+		Anime: map[string]*arn.Anime{
+			steinsGate.ID: steinsGate,
+			unmapped.ID:   unmapped,
+		},
+	}
+}
+
+func TestScaleRating(t *testing.T) {
+	assert.Equal(t, 0, arn.ScaleRating(0, 10))
+	assert.Equal(t, 1, arn.ScaleRating(0.1, 10))
+	assert.Equal(t, 9, arn.ScaleRating(9.44, 10))
+	assert.Equal(t, 94, arn.ScaleRating(9.44, 100))
+	assert.Equal(t, 10, arn.ScaleRating(12, 10))
+}
+
+func TestAnimeListExportUnmapped(t *testing.T) {
+	export := newTestExport()
+	unmapped := export.Unmapped("myanimelist/anime")
+
+	assert.Len(t, unmapped, 1)
+	assert.Equal(t, "unmapped", unmapped[0].AnimeID)
+	assert.Len(t, export.Unmapped("shoboi/anime"), 2)
+}
+
+func TestAnimeListExportMyAnimeListXML(t *testing.T) {
+	data, err := newTestExport().MyAnimeListXML()
+	assert.NoError(t, err)
+
+	xml := string(data)
+	assert.True(t, strings.HasPrefix(xml, "<?xml"))
+	assert.Contains(t, xml, "<user_name>Okabe</user_name>")
+	assert.Contains(t, xml, "<user_total_anime>1</user_total_anime>")
+	assert.Contains(t, xml, "<user_total_completed>1</user_total_completed>")
+	assert.Contains(t, xml, "<series_animedb_id>9253</series_animedb_id>")
+	assert.Contains(t, xml, "<series_title><![CDATA[Steins;Gate]]></series_title>")
+	assert.Contains(t, xml, "<my_score>9</my_score>")
+	assert.Contains(t, xml, "<my_status>Completed</my_status>")
+	assert.Contains(t, xml, "<my_comments><![CDATA[El Psy <Kongroo>]]></my_comments>")
+	assert.Contains(t, xml, "<my_times_watched>2</my_times_watched>")
+	assert.NotContains(t, xml, "Unmapped")
+}
+
+func TestAnimeListExportCSV(t *testing.T) {
+	data, err := newTestExport().CSV()
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "id,title,myanimelist,anilist,kitsu,status,episodes,rating,story,visuals,soundtrack,rewatchCount,private,notes", lines[0])
+	assert.Equal(t, "steins-gate,Steins;Gate,9253,9253,5646,completed,24,9.4,0,0,0,2,false,El Psy <Kongroo>", lines[1])
+	assert.Equal(t, "unmapped,Unmapped,,,,planned,0,0,0,0,0,0,false,", lines[2])
+}
+
+func TestAnimeListExportJSON(t *testing.T) {
+	data, err := newTestExport().JSON()
+	assert.NoError(t, err)
+
+	json := string(data)
+	assert.Contains(t, json, `"kitsu": "5646"`)
+	assert.Contains(t, json, `"score100": 94`)
+	assert.Contains(t, json, `"rewatchCount": 2`)
+}
@@ -0,0 +1,319 @@
+package arn
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Import sources
+const (
+	ImportSourceMyAnimeList = "myanimelist"
+	ImportSourceAniList     = "anilist"
+	ImportSourceKitsu       = "kitsu"
+)
+
+// Anime list item fields that can be changed by an import
+const (
+	ImportFieldStatus       = "status"
+	ImportFieldEpisodes     = "episodes"
+	ImportFieldRating       = "rating"
+	ImportFieldNotes        = "notes"
+	ImportFieldRewatchCount = "rewatchCount"
+)
+
+// Reasons for entries that can't be imported
+const (
+	ImportUnmatchedNoAnime   = "no-anime"
+	ImportUnmatchedDuplicate = "duplicate"
+)
+
+// AnimeListImportPlan is the preview of an anime list import.
+// It can be reviewed by the user before it gets applied to the list.
+type AnimeListImportPlan struct {
+	UserID    string                      `json:"userId"`
+	Source    string                      `json:"source"`
+	Policy    string                      `json:"policy"`
+	Added     []*AnimeListItem            `json:"added"`
+	Updated   []*AnimeListImportUpdate    `json:"updated"`
+	Conflicts []*AnimeListImportConflict  `json:"conflicts"`
+	Unmatched []*AnimeListImportUnmatched `json:"unmatched"`
+
+	policy  ImportPolicy
+	planned map[string]bool
+}
+
+// AnimeListImportUpdate describes the changes to an item that already exists in the list.
+type AnimeListImportUpdate struct {
+	AnimeID string                   `json:"animeId"`
+	Changes []*AnimeListImportChange `json:"changes"`
+	Item    *AnimeListItem           `json:"item"`
+
+	// edited is the edit date of the existing item when the plan was created.
+	edited string
+}
+
+// AnimeListImportChange is a changed field with its old and new value.
+type AnimeListImportChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// AnimeListImportConflict is a field where the imported value differs
+// from the existing one but the import policy keeps the existing value.
+type AnimeListImportConflict struct {
+	AnimeID  string      `json:"animeId"`
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Imported interface{} `json:"imported"`
+}
+
+// AnimeListImportUnmatched is an entry of the external list that can't be imported.
+type AnimeListImportUnmatched struct {
+	ServiceID string `json:"serviceId"`
+	Title     string `json:"title"`
+	Reason    string `json:"reason"`
+}
+
+// PlanMyAnimeListImport creates the import plan for a MyAnimeList anime list.
+func PlanMyAnimeListImport(list *AnimeList, matches []*MyAnimeListMatch, policy ImportPolicy) *AnimeListImportPlan {
+	plan := newAnimeListImportPlan(list, ImportSourceMyAnimeList, policy)
+
+	for _, match := range matches {
+		plan.add(list, match.ARNAnime, strconv.Itoa(match.MyAnimeListItem.AnimeID), match.MyAnimeListItem.AnimeTitle, match.ImportFields(), match.AnimeListItem)
+	}
+
+	return plan
+}
+
+// PlanAniListImport creates the import plan for an AniList anime list.
+func PlanAniListImport(list *AnimeList, matches []*AniListMatch, policy ImportPolicy) *AnimeListImportPlan {
+	plan := newAnimeListImportPlan(list, ImportSourceAniList, policy)
+
+	for _, match := range matches {
+		serviceID := ""
+		title := ""
+
+		if match.AniListItem.Anime != nil {
+			serviceID = strconv.Itoa(match.AniListItem.Anime.ID)
+			title = match.AniListItem.Anime.Title.Romaji
+		}
+
+		plan.add(list, match.ARNAnime, serviceID, title, match.ImportFields(), match.AnimeListItem)
+	}
+
+	return plan
+}
+
+// PlanKitsuImport creates the import plan for a Kitsu library.
+func PlanKitsuImport(list *AnimeList, matches []*KitsuMatch, policy ImportPolicy) *AnimeListImportPlan {
+	plan := newAnimeListImportPlan(list, ImportSourceKitsu, policy)
+
+	for _, match := range matches {
+		serviceID := ""
+		title := ""
+
+		if match.KitsuItem.Anime != nil {
+			serviceID = match.KitsuItem.Anime.ID
+			title = match.KitsuItem.Anime.Attributes.CanonicalTitle
+		}
+
+		plan.add(list, match.ARNAnime, serviceID, title, match.ImportFields(), match.AnimeListItem)
+	}
+
+	return plan
+}
+
+// newAnimeListImportPlan creates an empty import plan.
+func newAnimeListImportPlan(list *AnimeList, source string, policy ImportPolicy) *AnimeListImportPlan {
+	return &AnimeListImportPlan{
+		UserID:    list.UserID,
+		Source:    source,
+		Policy:    policy.Name(),
+		Added:     []*AnimeListItem{},
+		Updated:   []*AnimeListImportUpdate{},
+		Conflicts: []*AnimeListImportConflict{},
+		Unmatched: []*AnimeListImportUnmatched{},
+		policy:    policy,
+		planned:   map[string]bool{},
+	}
+}
+
+// add plans the import of a single entry using the same rules as AnimeList.ImportWithPolicy.
+// Fields that are not supplied by the import source keep their existing value.
+// The list itself is not modified.
+func (plan *AnimeListImportPlan) add(list *AnimeList, anime *Anime, serviceID string, title string, fields []string, importedItem func() *AnimeListItem) {
+	if anime == nil {
+		plan.unmatched(serviceID, title, ImportUnmatchedNoAnime)
+		return
+	}
+
+	if plan.planned[anime.ID] {
+		plan.unmatched(serviceID, title, ImportUnmatchedDuplicate)
+		return
+	}
+
+	plan.planned[anime.ID] = true
+	imported := importedItem()
+	existing := list.Find(anime.ID)
+
+	if existing == nil {
+		imported.Rating.Clamp()
+		plan.Added = append(plan.Added, imported)
+		return
+	}
+
+	policy := &suppliedFieldsImportPolicy{
+		ImportPolicy: plan.policy,
+		fields:       fields,
+	}
+
+	merged := *existing
+	merged.merge(imported, anime, policy)
+
+	plan.Conflicts = append(plan.Conflicts, importConflicts(existing, imported, policy, fields)...)
+	changes := importChanges(existing, &merged)
+
+	if len(changes) == 0 {
+		return
+	}
+
+	plan.Updated = append(plan.Updated, &AnimeListImportUpdate{
+		AnimeID: anime.ID,
+		Changes: changes,
+		Item:    &merged,
+		edited:  existing.Edited,
+	})
+}
+
+// unmatched adds an entry that can't be imported.
+func (plan *AnimeListImportPlan) unmatched(serviceID string, title string, reason string) {
+	plan.Unmatched = append(plan.Unmatched, &AnimeListImportUnmatched{
+		ServiceID: serviceID,
+		Title:     title,
+		Reason:    reason,
+	})
+}
+
+// HasChanges returns true if applying the plan would modify the list.
+func (plan *AnimeListImportPlan) HasChanges() bool {
+	return len(plan.Added) > 0 || len(plan.Updated) > 0
+}
+
+// Apply applies all planned changes to the list at once.
+// If the list has been modified in a way that affects the plan since it was created,
+// nothing is applied and an error is returned.
+func (plan *AnimeListImportPlan) Apply(list *AnimeList) error {
+	list.Lock()
+	defer list.Unlock()
+
+	if list.UserID != plan.UserID {
+		return errors.New("Import plan belongs to a different anime list")
+	}
+
+	indices := make(map[string]int, len(list.Items))
+
+	for index, item := range list.Items {
+		indices[item.AnimeID] = index
+	}
+
+	for _, item := range plan.Added {
+		_, exists := indices[item.AnimeID]
+
+		if exists {
+			return errors.New("Anime " + item.AnimeID + " has been added to the list after the import was planned")
+		}
+	}
+
+	for _, update := range plan.Updated {
+		index, exists := indices[update.AnimeID]
+
+		if !exists || list.Items[index].Edited != update.edited {
+			return errors.New("Anime " + update.AnimeID + " has been modified after the import was planned")
+		}
+	}
+
+	now := DateTimeUTC()
+	var previous, current []*AnimeListItem
+
+	for _, update := range plan.Updated {
+		item := *update.Item
+		item.Edited = now
+		previous = append(previous, list.Items[indices[update.AnimeID]])
+		current = append(current, &item)
+		list.Items[indices[update.AnimeID]] = &item
+	}
+
+	for _, added := range plan.Added {
+		item := *added
+
+		if item.Edited == "" {
+			item.Edited = now
+		}
+
+		previous = append(previous, &AnimeListItem{AnimeID: item.AnimeID})
+		current = append(current, &item)
+		list.Items = append(list.Items, &item)
+	}
+
+	// The watched episodes of all items are logged with a single save of the watch log
+	UpdateWatchLog(list.UserID, func(log *WatchLog) {
+		for index := range current {
+			log.AppendProgress(previous[index], current[index], now)
+		}
+	})
+
+	return nil
+}
+
+// importChanges returns the fields that differ between the existing and the merged item.
+func importChanges(existing *AnimeListItem, merged *AnimeListItem) []*AnimeListImportChange {
+	var changes []*AnimeListImportChange
+
+	for _, field := range importFields {
+		old := importFieldValue(existing, field)
+		new := importFieldValue(merged, field)
+
+		if old == new {
+			continue
+		}
+
+		changes = append(changes, &AnimeListImportChange{
+			Field: field,
+			Old:   old,
+			New:   new,
+		})
+	}
+
+	return changes
+}
+
+// importConflicts returns the fields where the policy keeps the existing value
+// although a different, non-empty value has been imported.
+// Only the given fields, which the import source supplies, are checked.
+func importConflicts(existing *AnimeListItem, imported *AnimeListItem, policy ImportPolicy, fields []string) []*AnimeListImportConflict {
+	var conflicts []*AnimeListImportConflict
+
+	for _, field := range fields {
+		current := importFieldValue(existing, field)
+		importedValue := importFieldValue(imported, field)
+
+		if current == importedValue || policy.Resolve(field, existing, imported) == ImportSideRemote {
+			continue
+		}
+
+		// Missing ratings and notes on the remote side are not considered conflicts.
+		if importedValue == 0.0 || importedValue == "" {
+			continue
+		}
+
+		conflicts = append(conflicts, &AnimeListImportConflict{
+			AnimeID:  existing.AnimeID,
+			Field:    field,
+			Current:  current,
+			Imported: importedValue,
+		})
+	}
+
+	return conflicts
+}
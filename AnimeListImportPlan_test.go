@@ -0,0 +1,227 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/anilist"
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+const testImportEdited = "2018-01-01T00:00:00Z"
+
+func newTestImportAnime(id string) *arn.Anime {
+	return &arn.Anime{
+		HasID:        arn.HasID{ID: id},
+		Status:       "finished",
+		EpisodeCount: 24,
+	}
+}
+
+func newTestImportMatch(anime *arn.Anime, status string, progress int, score int, notes string, repeat int) *arn.AniListMatch {
+	item := &anilist.AnimeListItem{
+		Status:   status,
+		Progress: progress,
+		ScoreRaw: score,
+		Notes:    notes,
+		Repeat:   repeat,
+		Anime:    &anilist.Anime{ID: 1},
+	}
+
+	return &arn.AniListMatch{
+		AniListItem: item,
+		ARNAnime:    anime,
+	}
+}
+
+func newTestImportList(items ...*arn.AnimeListItem) *arn.AnimeList {
+	for _, item := range items {
+		item.Edited = testImportEdited
+	}
+
+	return &arn.AnimeList{
+		UserID: "4J6qpK1ve",
+		Items:  items,
+	}
+}
+
+func findImportChange(update *arn.AnimeListImportUpdate, field string) *arn.AnimeListImportChange {
+	for _, change := range update.Changes {
+		if change.Field == field {
+			return change
+		}
+	}
+
+	return nil
+}
+
+func TestImportPlanAdd(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list := newTestImportList()
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "CURRENT", 3, 85, "Good", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Len(t, plan.Added, 1)
+	assert.Empty(t, plan.Updated)
+	assert.Equal(t, arn.AnimeListStatusWatching, plan.Added[0].Status)
+	assert.Equal(t, 3, plan.Added[0].Episodes)
+	assert.Equal(t, 8.5, plan.Added[0].Rating.Overall)
+	assert.Equal(t, "Good", plan.Added[0].Notes)
+	assert.Empty(t, list.Items)
+}
+
+func TestImportPlanStatusAlwaysImported(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list := newTestImportList(&arn.AnimeListItem{AnimeID: anime.ID, Status: arn.AnimeListStatusWatching, Episodes: 5})
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "PAUSED", 5, 0, "", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Len(t, plan.Updated, 1)
+	change := findImportChange(plan.Updated[0], arn.ImportFieldStatus)
+	assert.NotNil(t, change)
+	assert.Equal(t, arn.AnimeListStatusWatching, change.Old)
+	assert.Equal(t, arn.AnimeListStatusHold, change.New)
+	assert.Empty(t, plan.Conflicts)
+}
+
+func TestImportPlanHigherEpisodesKept(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list := newTestImportList(&arn.AnimeListItem{AnimeID: anime.ID, Status: arn.AnimeListStatusWatching, Episodes: 10})
+
+	// Higher episode count is imported
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "CURRENT", 12, 0, "", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Len(t, plan.Updated, 1)
+	assert.Equal(t, 12, plan.Updated[0].Item.Episodes)
+	assert.Empty(t, plan.Conflicts)
+
+	// Lower episode count is a conflict
+	plan = arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "CURRENT", 4, 0, "", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Empty(t, plan.Updated)
+	assert.Len(t, plan.Conflicts, 1)
+	assert.Equal(t, arn.ImportFieldEpisodes, plan.Conflicts[0].Field)
+	assert.Equal(t, 10, plan.Conflicts[0].Current)
+	assert.Equal(t, 4, plan.Conflicts[0].Imported)
+}
+
+func TestImportPlanRatingOnlyFilledIfEmpty(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	unrated := &arn.AnimeListItem{AnimeID: anime.ID, Status: arn.AnimeListStatusWatching, Episodes: 5}
+	list := newTestImportList(unrated)
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "CURRENT", 5, 70, "", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Len(t, plan.Updated, 1)
+	assert.Equal(t, 7.0, findImportChange(plan.Updated[0], arn.ImportFieldRating).New)
+
+	unrated.Rating.Overall = 9
+	plan = arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "CURRENT", 5, 70, "", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Empty(t, plan.Updated)
+	assert.Len(t, plan.Conflicts, 1)
+	assert.Equal(t, arn.ImportFieldRating, plan.Conflicts[0].Field)
+}
+
+func TestImportPlanNotesOnlyFilledIfEmpty(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	item := &arn.AnimeListItem{AnimeID: anime.ID, Status: arn.AnimeListStatusWatching, Episodes: 5}
+	list := newTestImportList(item)
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "CURRENT", 5, 0, "Imported", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Len(t, plan.Updated, 1)
+	assert.Equal(t, "Imported", findImportChange(plan.Updated[0], arn.ImportFieldNotes).New)
+
+	item.Notes = "Mine"
+	plan = arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "CURRENT", 5, 0, "Imported", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Empty(t, plan.Updated)
+	assert.Len(t, plan.Conflicts, 1)
+	assert.Equal(t, "Mine", plan.Conflicts[0].Current)
+	assert.Equal(t, "Imported", plan.Conflicts[0].Imported)
+}
+
+func TestImportPlanRewatchCountOnlyIncreased(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list := newTestImportList(&arn.AnimeListItem{AnimeID: anime.ID, Status: arn.AnimeListStatusCompleted, Episodes: 24, RewatchCount: 2})
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "COMPLETED", 24, 0, "", 3),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Len(t, plan.Updated, 1)
+	assert.Equal(t, 3, plan.Updated[0].Item.RewatchCount)
+
+	plan = arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "COMPLETED", 24, 0, "", 1),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Empty(t, plan.Updated)
+	assert.Len(t, plan.Conflicts, 1)
+	assert.Equal(t, arn.ImportFieldRewatchCount, plan.Conflicts[0].Field)
+}
+
+func TestImportPlanUnmatched(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list := newTestImportList()
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(nil, "CURRENT", 1, 0, "", 0),
+		newTestImportMatch(anime, "CURRENT", 1, 0, "", 0),
+		newTestImportMatch(anime, "COMPLETED", 24, 0, "", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.Len(t, plan.Added, 1)
+	assert.Len(t, plan.Unmatched, 2)
+	assert.Equal(t, arn.ImportUnmatchedNoAnime, plan.Unmatched[0].Reason)
+	assert.Equal(t, "1", plan.Unmatched[0].ServiceID)
+	assert.Equal(t, arn.ImportUnmatchedDuplicate, plan.Unmatched[1].Reason)
+}
+
+func TestImportPlanApply(t *testing.T) {
+	erased := newTestImportAnime("erased")
+	clannad := newTestImportAnime("clannad")
+	existing := &arn.AnimeListItem{AnimeID: erased.ID, Status: arn.AnimeListStatusWatching, Episodes: 5}
+	list := newTestImportList(existing)
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(erased, "CURRENT", 8, 0, "", 0),
+		newTestImportMatch(clannad, "PLANNING", 0, 0, "", 0),
+	}, &arn.MergeImportPolicy{})
+
+	assert.True(t, plan.HasChanges())
+	assert.Equal(t, 5, existing.Episodes)
+
+	// Modifications after planning prevent the whole plan from being applied
+	existing.Edited = arn.DateTimeUTC()
+	assert.Error(t, plan.Apply(list))
+	assert.Len(t, list.Items, 1)
+	assert.Equal(t, 5, list.Items[0].Episodes)
+
+	existing.Edited = testImportEdited
+	assert.NoError(t, plan.Apply(list))
+	defer arn.DB.Delete("WatchLog", list.UserID)
+	assert.Len(t, list.Items, 2)
+	assert.Equal(t, 8, list.Find(erased.ID).Episodes)
+	assert.Equal(t, arn.AnimeListStatusPlanned, list.Find(clannad.ID).Status)
+
+	// The imported episodes are logged
+	log, err := arn.GetWatchLog(list.UserID)
+	assert.NoError(t, err)
+	assert.Len(t, log.Entries(erased.ID), 3)
+	assert.Equal(t, 6, log.Entries(erased.ID)[0].Episode)
+	assert.Empty(t, log.Entries(clannad.ID))
+
+	// Applying twice fails because the items have been edited by the first run
+	assert.Error(t, plan.Apply(list))
+}
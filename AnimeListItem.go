@@ -53,16 +53,21 @@ func (item *AnimeListItem) StatusHumanReadable() string {
 
 // OnEpisodesChange is called when the watched episode count changes.
 func (item *AnimeListItem) OnEpisodesChange() {
-	maxEpisodesKnown := item.Anime().EpisodeCount != 0
+	item.onEpisodesChange(item.Anime())
+}
+
+// onEpisodesChange updates the status after an episode change using the given anime data.
+func (item *AnimeListItem) onEpisodesChange(anime *Anime) {
+	maxEpisodesKnown := anime.EpisodeCount != 0
 
 	// If we update episodes to the max, set status to completed automatically.
-	if item.Anime().Status == "finished" && maxEpisodesKnown && item.Episodes == item.Anime().EpisodeCount {
+	if anime.Status == "finished" && maxEpisodesKnown && item.Episodes == anime.EpisodeCount {
 		// Complete automatically.
 		item.Status = AnimeListStatusCompleted
 	}
 
 	// We set episodes lower than the max but the status is set as completed.
-	if item.Status == AnimeListStatusCompleted && maxEpisodesKnown && item.Episodes < item.Anime().EpisodeCount {
+	if item.Status == AnimeListStatusCompleted && maxEpisodesKnown && item.Episodes < anime.EpisodeCount {
 		// Set status back to watching.
 		item.Status = AnimeListStatusWatching
 	}
@@ -82,12 +87,17 @@ func (item *AnimeListItem) OnEpisodesChange() {
 
 // OnStatusChange is called when the status changes.
 func (item *AnimeListItem) OnStatusChange() {
-	maxEpisodesKnown := item.Anime().EpisodeCount != 0
+	item.onStatusChange(item.Anime())
+}
+
+// onStatusChange updates the episodes after a status change using the given anime data.
+func (item *AnimeListItem) onStatusChange(anime *Anime) {
+	maxEpisodesKnown := anime.EpisodeCount != 0
 
 	// We just switched to completed status but the episodes aren't max yet.
-	if item.Status == AnimeListStatusCompleted && maxEpisodesKnown && item.Episodes < item.Anime().EpisodeCount {
+	if item.Status == AnimeListStatusCompleted && maxEpisodesKnown && item.Episodes < anime.EpisodeCount {
 		// Set episodes to max.
-		item.Episodes = item.Anime().EpisodeCount
+		item.Episodes = anime.EpisodeCount
 	}
 
 	// We just switched to plan to watch status but the episodes are greater than zero.
@@ -97,8 +107,48 @@ func (item *AnimeListItem) OnStatusChange() {
 	}
 
 	// If we have an anime with max episodes watched and we change status to not completed, lower episode count by 1.
-	if maxEpisodesKnown && item.Status != AnimeListStatusCompleted && item.Episodes == item.Anime().EpisodeCount {
+	if maxEpisodesKnown && item.Status != AnimeListStatusCompleted && item.Episodes == anime.EpisodeCount {
 		// Lower episodes by 1.
 		item.Episodes--
 	}
 }
+
+// merge merges the imported item into the item.
+// The policy decides which side wins for every field that differs.
+func (item *AnimeListItem) merge(imported *AnimeListItem, anime *Anime, policy ImportPolicy) {
+	local := *item
+
+	// Status
+	if policy.Resolve(ImportFieldStatus, &local, imported) == ImportSideRemote {
+		item.Status = imported.Status
+	}
+
+	item.onStatusChange(anime)
+
+	// Status changes can modify the episode count,
+	// therefore the episodes are set after the status change.
+	// This will prevent loss of "episodes watched" data.
+	if policy.Resolve(ImportFieldEpisodes, &local, imported) == ImportSideRemote {
+		item.Episodes = imported.Episodes
+	} else {
+		item.Episodes = local.Episodes
+	}
+
+	item.onEpisodesChange(anime)
+
+	// Rating
+	if policy.Resolve(ImportFieldRating, &local, imported) == ImportSideRemote {
+		item.Rating.Overall = imported.Rating.Overall
+		item.Rating.Clamp()
+	}
+
+	// Notes
+	if policy.Resolve(ImportFieldNotes, &local, imported) == ImportSideRemote {
+		item.Notes = imported.Notes
+	}
+
+	// Rewatch count
+	if policy.Resolve(ImportFieldRewatchCount, &local, imported) == ImportSideRemote {
+		item.RewatchCount = imported.RewatchCount
+	}
+}
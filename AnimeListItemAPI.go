@@ -24,6 +24,19 @@ func (item *AnimeListItem) Edit(ctx *aero.Context, key string, value reflect.Val
 		return true, errors.New("Not logged in")
 	}
 
+	// Episodes can also change with the status, all changes are logged here
+	previous := *item
+	consumed, err := item.edit(user, key, newValue)
+
+	if err == nil {
+		logWatchProgress(user.ID, &previous, item)
+	}
+
+	return consumed, err
+}
+
+// edit applies the edit of a single field.
+func (item *AnimeListItem) edit(user *User, key string, newValue reflect.Value) (bool, error) {
 	switch key {
 	case "Episodes":
 		oldEpisodes := item.Episodes
@@ -66,6 +79,15 @@ func (item *AnimeListItem) Edit(ctx *aero.Context, key string, value reflect.Val
 		item.OnEpisodesChange()
 		return true, nil
 
+	case "RewatchCount":
+		item.RewatchCount = int(newValue.Float())
+
+		if item.RewatchCount < 0 {
+			item.RewatchCount = 0
+		}
+
+		return true, nil
+
 	case "Status":
 		newStatus := newValue.String()
 
@@ -1,5 +1,7 @@
 package arn
 
+import "math"
+
 // DefaultRating is the default rating value.
 const DefaultRating = 0.0
 
@@ -12,14 +14,29 @@ const AverageRating = 5.0
 const MaxRating = 10.0
 
 // RatingCountThreshold is the number of users threshold that, when passed, doesn't dampen the result.
+// It is also used as the weight of the prior in the Bayesian average.
 const RatingCountThreshold = 4
 
+// ratingConfidenceZ is the z-score for 95% confidence intervals.
+const ratingConfidenceZ = 1.96
+
+// Rating categories
+const (
+	RatingCategoryOverall    = "overall"
+	RatingCategoryStory      = "story"
+	RatingCategoryVisuals    = "visuals"
+	RatingCategorySoundtrack = "soundtrack"
+)
+
 // AnimeRating ...
 type AnimeRating struct {
 	AnimeListItemRating
 
 	// The amount of people who rated
 	Count AnimeRatingCount `json:"count"`
+
+	// The standard deviation of the ratings
+	Deviation AnimeListItemRating `json:"deviation"`
 }
 
 // AnimeRatingCount ...
@@ -29,3 +46,167 @@ type AnimeRatingCount struct {
 	Visuals    int `json:"visuals"`
 	Soundtrack int `json:"soundtrack"`
 }
+
+// Bayesian returns the Bayesian average of every category.
+// Anime with only a few ratings are pulled towards AverageRating.
+func (rating *AnimeRating) Bayesian() AnimeListItemRating {
+	return AnimeListItemRating{
+		Overall:    BayesianRating(rating.Overall, rating.Count.Overall),
+		Story:      BayesianRating(rating.Story, rating.Count.Story),
+		Visuals:    BayesianRating(rating.Visuals, rating.Count.Visuals),
+		Soundtrack: BayesianRating(rating.Soundtrack, rating.Count.Soundtrack),
+	}
+}
+
+// ConfidenceInterval returns the 95% confidence interval of the Bayesian average for the category.
+// Categories without any ratings return the full rating scale.
+func (rating *AnimeRating) ConfidenceInterval(category string) (low float64, high float64) {
+	var average, deviation float64
+	var count int
+
+	switch category {
+	case RatingCategoryOverall:
+		average, deviation, count = rating.Overall, rating.Deviation.Overall, rating.Count.Overall
+	case RatingCategoryStory:
+		average, deviation, count = rating.Story, rating.Deviation.Story, rating.Count.Story
+	case RatingCategoryVisuals:
+		average, deviation, count = rating.Visuals, rating.Deviation.Visuals, rating.Count.Visuals
+	case RatingCategorySoundtrack:
+		average, deviation, count = rating.Soundtrack, rating.Deviation.Soundtrack, rating.Count.Soundtrack
+	}
+
+	if count == 0 {
+		return 0, MaxRating
+	}
+
+	center := BayesianRating(average, count)
+	margin := ratingConfidenceZ * deviation / math.Sqrt(float64(count))
+
+	return math.Max(center-margin, 0), math.Min(center+margin, MaxRating)
+}
+
+// BayesianRating returns the Bayesian average for the given average and number of ratings,
+// using AverageRating as the prior with a weight of RatingCountThreshold ratings.
+func BayesianRating(average float64, count int) float64 {
+	return (AverageRating*RatingCountThreshold + average*float64(count)) / float64(RatingCountThreshold+count)
+}
+
+// ratingAccumulator collects the sum and squared sum of ratings in a single category.
+type ratingAccumulator struct {
+	sum        float64
+	squaredSum float64
+	count      int
+}
+
+// add adds a rating, zero counts as not rated.
+func (accumulator *ratingAccumulator) add(value float64) {
+	if value == 0 {
+		return
+	}
+
+	accumulator.sum += value
+	accumulator.squaredSum += value * value
+	accumulator.count++
+}
+
+// average returns the average of all ratings.
+func (accumulator *ratingAccumulator) average() float64 {
+	if accumulator.count == 0 {
+		return DefaultRating
+	}
+
+	return accumulator.sum / float64(accumulator.count)
+}
+
+// deviation returns the population standard deviation of all ratings.
+func (accumulator *ratingAccumulator) deviation() float64 {
+	if accumulator.count == 0 {
+		return 0
+	}
+
+	average := accumulator.average()
+	variance := accumulator.squaredSum/float64(accumulator.count) - average*average
+	return math.Sqrt(math.Max(variance, 0))
+}
+
+// animeRatingAccumulator collects the ratings of all categories for an anime.
+type animeRatingAccumulator struct {
+	overall    ratingAccumulator
+	story      ratingAccumulator
+	visuals    ratingAccumulator
+	soundtrack ratingAccumulator
+}
+
+// CalculateAnimeRatings calculates the ratings of all anime appearing in the given lists.
+func CalculateAnimeRatings(lists []*AnimeList) map[string]*AnimeRating {
+	accumulators := map[string]*animeRatingAccumulator{}
+
+	for _, list := range lists {
+		list.Lock()
+
+		for _, item := range list.Items {
+			categories, exists := accumulators[item.AnimeID]
+
+			if !exists {
+				categories = &animeRatingAccumulator{}
+				accumulators[item.AnimeID] = categories
+			}
+
+			categories.overall.add(item.Rating.Overall)
+			categories.story.add(item.Rating.Story)
+			categories.visuals.add(item.Rating.Visuals)
+			categories.soundtrack.add(item.Rating.Soundtrack)
+		}
+
+		list.Unlock()
+	}
+
+	ratings := make(map[string]*AnimeRating, len(accumulators))
+
+	for animeID, categories := range accumulators {
+		ratings[animeID] = &AnimeRating{
+			AnimeListItemRating: AnimeListItemRating{
+				Overall:    categories.overall.average(),
+				Story:      categories.story.average(),
+				Visuals:    categories.visuals.average(),
+				Soundtrack: categories.soundtrack.average(),
+			},
+			Count: AnimeRatingCount{
+				Overall:    categories.overall.count,
+				Story:      categories.story.count,
+				Visuals:    categories.visuals.count,
+				Soundtrack: categories.soundtrack.count,
+			},
+			Deviation: AnimeListItemRating{
+				Overall:    categories.overall.deviation(),
+				Story:      categories.story.deviation(),
+				Visuals:    categories.visuals.deviation(),
+				Soundtrack: categories.soundtrack.deviation(),
+			},
+		}
+	}
+
+	return ratings
+}
+
+// RecalculateAnimeRatings rebuilds the ratings of all anime from all anime lists
+// and saves the anime whose rating changed. This is meant to be run as a batch job.
+func RecalculateAnimeRatings() {
+	lists, _ := AllAnimeLists()
+	ratings := CalculateAnimeRatings(lists)
+
+	for anime := range StreamAnime() {
+		rating, exists := ratings[anime.ID]
+
+		if !exists {
+			rating = &AnimeRating{}
+		}
+
+		if anime.Rating != nil && *anime.Rating == *rating {
+			continue
+		}
+
+		anime.Rating = rating
+		anime.Save()
+	}
+}
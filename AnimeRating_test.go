@@ -0,0 +1,59 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBayesianRating(t *testing.T) {
+	assert.Equal(t, arn.AverageRating, arn.BayesianRating(10, 0))
+	assert.InDelta(t, 7.5, arn.BayesianRating(10, arn.RatingCountThreshold), 0.0001)
+	assert.True(t, arn.BayesianRating(9, 1000) > arn.BayesianRating(10, 2))
+}
+
+func TestCalculateAnimeRatings(t *testing.T) {
+	lists := []*arn.AnimeList{
+		newTestList("a", map[string]float64{"steins-gate": 10, "erased": 6}),
+		newTestList("b", map[string]float64{"steins-gate": 8, "erased": 0}),
+	}
+
+	lists[0].Find("steins-gate").Rating.Story = 9
+
+	ratings := arn.CalculateAnimeRatings(lists)
+	rating := ratings["steins-gate"]
+
+	assert.Len(t, ratings, 2)
+	assert.Equal(t, 9.0, rating.Overall)
+	assert.Equal(t, 2, rating.Count.Overall)
+	assert.Equal(t, 1.0, rating.Deviation.Overall)
+	assert.Equal(t, 9.0, rating.Story)
+	assert.Equal(t, 1, rating.Count.Story)
+	assert.Equal(t, 0, rating.Count.Visuals)
+	assert.Equal(t, 6.0, ratings["erased"].Overall)
+	assert.Equal(t, 1, ratings["erased"].Count.Overall)
+
+	bayesian := rating.Bayesian()
+	assert.InDelta(t, (arn.AverageRating*arn.RatingCountThreshold+18)/(arn.RatingCountThreshold+2), bayesian.Overall, 0.0001)
+	assert.Equal(t, arn.AverageRating, bayesian.Visuals)
+
+	low, high := rating.ConfidenceInterval(arn.RatingCategoryOverall)
+	assert.True(t, low < bayesian.Overall && bayesian.Overall < high)
+
+	low, high = rating.ConfidenceInterval(arn.RatingCategorySoundtrack)
+	assert.Equal(t, 0.0, low)
+	assert.Equal(t, arn.MaxRating, high)
+}
+
+func TestScoreUsesBayesianRating(t *testing.T) {
+	fewRatings := &arn.Anime{Rating: &arn.AnimeRating{}, Popularity: &arn.AnimePopularity{}}
+	fewRatings.Rating.Overall = 10
+	fewRatings.Rating.Count.Overall = 1
+
+	manyRatings := &arn.Anime{Rating: &arn.AnimeRating{}, Popularity: &arn.AnimePopularity{}}
+	manyRatings.Rating.Overall = 8.5
+	manyRatings.Rating.Count.Overall = 500
+
+	assert.True(t, manyRatings.Score() > fewRatings.Score())
+}
@@ -0,0 +1,134 @@
+package arn
+
+import (
+	"fmt"
+
+	"github.com/aerogo/nano"
+)
+
+// RecommendationsPerUser is the number of recommendations stored for each user.
+const RecommendationsPerUser = 50
+
+// AnimeRecommendations is the cached list of recommended anime for a user.
+type AnimeRecommendations struct {
+	UserID  string                 `json:"userId"`
+	Items   []*AnimeRecommendation `json:"items"`
+	Created string                 `json:"created"`
+}
+
+// AnimeRecommendation is a single recommended anime including the reason for the recommendation.
+type AnimeRecommendation struct {
+	AnimeID   string  `json:"animeId"`
+	Score     float64 `json:"score"`
+	Reason    string  `json:"reason"`
+	BecauseOf string  `json:"becauseOf"`
+}
+
+// Anime returns the recommended anime.
+func (recommendation *AnimeRecommendation) Anime() *Anime {
+	anime, _ := GetAnime(recommendation.AnimeID)
+	return anime
+}
+
+// BecauseOfAnime returns the anime in the user's list that led to the recommendation.
+func (recommendation *AnimeRecommendation) BecauseOfAnime() *Anime {
+	anime, _ := GetAnime(recommendation.BecauseOf)
+	return anime
+}
+
+// Explanation returns a human readable explanation for the recommendation.
+func (recommendation *AnimeRecommendation) Explanation(user *User) string {
+	title := recommendation.BecauseOf
+	anime := recommendation.BecauseOfAnime()
+
+	if anime != nil {
+		title = anime.TitleByUser(user)
+	}
+
+	switch recommendation.Reason {
+	case RecommendationReasonRated:
+		return fmt.Sprintf("Because you rated %s highly", title)
+	case RecommendationReasonRelated:
+		return fmt.Sprintf("Because it's related to %s", title)
+	default:
+		return fmt.Sprintf("Because you watched %s", title)
+	}
+}
+
+// RecommendedAnime returns the top n cached anime recommendations for the user.
+// The recommendations are calculated by RefreshAnimeRecommendations.
+func (user *User) RecommendedAnime(n int) []*AnimeRecommendation {
+	recommendations, err := GetAnimeRecommendations(user.ID)
+
+	if err != nil {
+		return nil
+	}
+
+	items := recommendations.Items
+
+	if len(items) > n {
+		items = items[:n]
+	}
+
+	return items
+}
+
+// RefreshAnimeRecommendations builds a new recommendation model from all anime lists
+// and stores the recommendations for every user. This is meant to be run as a batch job.
+func RefreshAnimeRecommendations() {
+	lists, _ := AllAnimeLists()
+	var relations []*AnimeRelations
+
+	for animeRelations := range StreamAnimeRelations() {
+		relations = append(relations, animeRelations)
+	}
+
+	model := NewRecommendationModel(lists, AllAnime(), relations)
+	now := DateTimeUTC()
+
+	for _, list := range lists {
+		recommendations := &AnimeRecommendations{
+			UserID:  list.UserID,
+			Items:   model.Recommend(list, RecommendationsPerUser),
+			Created: now,
+		}
+
+		recommendations.Save()
+	}
+}
+
+// TypeName returns the type name.
+func (recommendations *AnimeRecommendations) TypeName() string {
+	return "AnimeRecommendations"
+}
+
+// Save saves the recommendations in the database.
+func (recommendations *AnimeRecommendations) Save() {
+	DB.Set("AnimeRecommendations", recommendations.UserID, recommendations)
+}
+
+// GetAnimeRecommendations returns the cached recommendations for the given user ID.
+func GetAnimeRecommendations(userID string) (*AnimeRecommendations, error) {
+	obj, err := DB.Get("AnimeRecommendations", userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*AnimeRecommendations), nil
+}
+
+// StreamAnimeRecommendations returns a stream of all cached recommendations.
+func StreamAnimeRecommendations() chan *AnimeRecommendations {
+	channel := make(chan *AnimeRecommendations, nano.ChannelBufferSize)
+
+	go func() {
+		for obj := range DB.All("AnimeRecommendations") {
+			channel <- obj.(*AnimeRecommendations)
+		}
+
+		close(channel)
+	}()
+
+	return channel
+}
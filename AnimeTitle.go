@@ -16,22 +16,28 @@ func (title *AnimeTitle) ByUser(user *User) string {
 		return title.Canonical
 	}
 
-	switch user.Settings().TitleLanguage {
-	case "canonical":
+	return title.ByLanguage(user.Settings().TitleLanguage)
+}
+
+// ByLanguage returns the title in the given title language.
+// Falls back to the canonical title if there is no title in that language.
+func (title *AnimeTitle) ByLanguage(language string) string {
+	switch language {
+	case TitleLanguageCanonical:
 		return title.Canonical
-	case "romaji":
+	case TitleLanguageRomaji:
 		if title.Romaji == "" {
 			return title.Canonical
 		}
 
 		return title.Romaji
-	case "english":
+	case TitleLanguageEnglish:
 		if title.English == "" {
 			return title.Canonical
 		}
 
 		return title.English
-	case "japanese":
+	case TitleLanguageJapanese:
 		if title.Japanese == "" {
 			return title.Canonical
 		}
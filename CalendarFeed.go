@@ -0,0 +1,316 @@
+package arn
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// CalendarFeedHistory is how long episodes stay in the feed after they started airing.
+	CalendarFeedHistory = 7 * 24 * time.Hour
+
+	// calendarProductID identifies the software that created the feed.
+	calendarProductID = "-//notify.moe//Anime Calendar//EN"
+
+	// calendarTimeFormat is the UTC date-time format of iCalendar.
+	calendarTimeFormat = "20060102T150405Z"
+
+	// calendarLineLength is the maximum number of octets per line, excluding the line break.
+	calendarLineLength = 75
+
+	// calendarAiringCacheDuration is how long the episodes of all airing anime are cached.
+	calendarAiringCacheDuration = 15 * time.Minute
+)
+
+// calendarAiringCache contains the episodes of all airing anime.
+// They are the same for every user who opted into seeing all anime.
+var calendarAiringCache struct {
+	sync.Mutex
+	value   []*UpcomingEpisode
+	created time.Time
+}
+
+// CalendarFeed is an iCalendar (RFC 5545) feed of airing episodes.
+type CalendarFeed struct {
+	Name     string
+	TimeZone *time.Location
+	Created  time.Time
+	Events   []*CalendarEvent
+}
+
+// CalendarEvent is the airing of a single episode.
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+}
+
+// NewCalendarFeed creates the calendar feed for the given episodes.
+// Titles are shown in the title language of the settings and episodes that
+// started airing before the feed history are left out.
+func NewCalendarFeed(user *User, settings *Settings, episodes []*UpcomingEpisode, now time.Time) *CalendarFeed {
+	feed := &CalendarFeed{
+		Name:     user.Nick + "'s anime calendar",
+		TimeZone: user.TimeZone(),
+		Created:  now.UTC(),
+		Events:   []*CalendarEvent{},
+	}
+
+	oldest := now.Add(-CalendarFeedHistory)
+
+	for _, upcoming := range episodes {
+		event := newCalendarEvent(upcoming.Anime, upcoming.Episode, settings.TitleLanguage)
+
+		if event == nil || event.Start.Before(oldest) {
+			continue
+		}
+
+		feed.Events = append(feed.Events, event)
+	}
+
+	sort.Slice(feed.Events, func(i, j int) bool {
+		a := feed.Events[i]
+		b := feed.Events[j]
+
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+
+		return a.UID < b.UID
+	})
+
+	return feed
+}
+
+// CalendarFeed returns the calendar feed of the user.
+func (user *User) CalendarFeed() *CalendarFeed {
+	settings := user.Settings()
+	now := time.Now()
+	return NewCalendarFeed(user, settings, user.CalendarEpisodes(settings, now), now)
+}
+
+// CalendarEpisodes returns the episodes shown in the calendar of the user,
+// including the episodes aired within the feed history before now.
+// The episodes are limited to the anime on the watching and planned lists
+// unless the user opted into all anime with upcoming episodes in the calendar settings.
+func (user *User) CalendarEpisodes(settings *Settings, now time.Time) []*UpcomingEpisode {
+	if settings.Calendar.FeedAllAnime {
+		return airingCalendarEpisodes(now)
+	}
+
+	var episodes []*UpcomingEpisode
+	animeList := user.AnimeList()
+
+	if animeList == nil {
+		return episodes
+	}
+
+	for _, item := range animeList.Items {
+		if item.Status != AnimeListStatusWatching && item.Status != AnimeListStatusPlanned {
+			continue
+		}
+
+		anime := item.Anime()
+
+		if anime == nil {
+			continue
+		}
+
+		animeEpisodes := anime.Episodes()
+
+		if animeEpisodes == nil {
+			continue
+		}
+
+		episodes = append(episodes, calendarEpisodes(anime, animeEpisodes, now)...)
+	}
+
+	return episodes
+}
+
+// airingCalendarEpisodes returns the episodes of all current and upcoming anime.
+// The result is cached for calendarAiringCacheDuration.
+func airingCalendarEpisodes(now time.Time) []*UpcomingEpisode {
+	calendarAiringCache.Lock()
+	defer calendarAiringCache.Unlock()
+
+	age := now.Sub(calendarAiringCache.created)
+
+	if calendarAiringCache.value == nil || age < 0 || age > calendarAiringCacheDuration {
+		calendarAiringCache.value = loadAiringCalendarEpisodes(now)
+		calendarAiringCache.created = now
+	}
+
+	// Callers get their own copy of the shared slice
+	episodes := make([]*UpcomingEpisode, len(calendarAiringCache.value))
+	copy(episodes, calendarAiringCache.value)
+	return episodes
+}
+
+// loadAiringCalendarEpisodes loads the episodes of all current and upcoming anime from the database.
+func loadAiringCalendarEpisodes(now time.Time) []*UpcomingEpisode {
+	episodes := []*UpcomingEpisode{}
+	var animes []*Anime
+	var animeIDs []string
+
+	for anime := range StreamAnime() {
+		if anime.Status != "current" && anime.Status != "upcoming" {
+			continue
+		}
+
+		animes = append(animes, anime)
+		animeIDs = append(animeIDs, anime.ID)
+	}
+
+	for index, obj := range DB.GetMany("AnimeEpisodes", animeIDs) {
+		if obj == nil {
+			continue
+		}
+
+		episodes = append(episodes, calendarEpisodes(animes[index], obj.(*AnimeEpisodes), now)...)
+	}
+
+	return episodes
+}
+
+// ICS returns the feed in the iCalendar format.
+func (feed *CalendarFeed) ICS() []byte {
+	buffer := bytes.Buffer{}
+	stamp := feed.Created.UTC().Format(calendarTimeFormat)
+
+	writeCalendarLine(&buffer, "BEGIN:VCALENDAR")
+	writeCalendarLine(&buffer, "VERSION:2.0")
+	writeCalendarLine(&buffer, "PRODID:"+calendarProductID)
+	writeCalendarLine(&buffer, "CALSCALE:GREGORIAN")
+	writeCalendarLine(&buffer, "METHOD:PUBLISH")
+	writeCalendarLine(&buffer, "X-WR-CALNAME:"+escapeCalendarText(feed.Name))
+
+	// Event times are always in UTC, the time zone only tells
+	// calendar applications how to display them.
+	// Fixed offsets have no time zone name that calendar applications understand.
+	if feed.TimeZone != nil && feed.TimeZone != time.UTC && feed.TimeZone != time.Local {
+		_, err := time.LoadLocation(feed.TimeZone.String())
+
+		if err == nil {
+			writeCalendarLine(&buffer, "X-WR-TIMEZONE:"+feed.TimeZone.String())
+		}
+	}
+
+	for _, event := range feed.Events {
+		writeCalendarLine(&buffer, "BEGIN:VEVENT")
+		writeCalendarLine(&buffer, "UID:"+event.UID)
+		writeCalendarLine(&buffer, "DTSTAMP:"+stamp)
+		writeCalendarLine(&buffer, "DTSTART:"+event.Start.UTC().Format(calendarTimeFormat))
+		writeCalendarLine(&buffer, "DTEND:"+event.End.UTC().Format(calendarTimeFormat))
+		writeCalendarLine(&buffer, "SUMMARY:"+escapeCalendarText(event.Summary))
+
+		if event.Description != "" {
+			writeCalendarLine(&buffer, "DESCRIPTION:"+escapeCalendarText(event.Description))
+		}
+
+		writeCalendarLine(&buffer, "URL:"+event.URL)
+		writeCalendarLine(&buffer, "END:VEVENT")
+	}
+
+	writeCalendarLine(&buffer, "END:VCALENDAR")
+	return buffer.Bytes()
+}
+
+// calendarEpisodes returns the episodes of the anime that haven't aired before the feed history.
+func calendarEpisodes(anime *Anime, animeEpisodes *AnimeEpisodes, now time.Time) []*UpcomingEpisode {
+	var episodes []*UpcomingEpisode
+	oldest := now.Add(-CalendarFeedHistory).UTC().Format(time.RFC3339)
+
+	for _, episode := range animeEpisodes.Items {
+		if episode.AiringDate.Start > oldest {
+			episodes = append(episodes, &UpcomingEpisode{
+				Anime:   anime,
+				Episode: episode,
+			})
+		}
+	}
+
+	return episodes
+}
+
+// newCalendarEvent creates the event for the episode.
+// Returns nil if the episode doesn't have a valid airing date.
+func newCalendarEvent(anime *Anime, episode *AnimeEpisode, titleLanguage string) *CalendarEvent {
+	start, err := time.Parse(time.RFC3339, episode.AiringDate.Start)
+
+	if err != nil {
+		return nil
+	}
+
+	end, err := time.Parse(time.RFC3339, episode.AiringDate.End)
+
+	if err != nil || !end.After(start) {
+		end = start.Add(anime.EpisodeDuration())
+	}
+
+	link := "https://notify.moe" + anime.Link()
+	description := link
+	episodeTitle := episode.Title.ByLanguage(titleLanguage)
+
+	if episodeTitle != "" {
+		description = episodeTitle + "\n" + link
+	}
+
+	title := anime.ID
+
+	if anime.Title != nil {
+		title = anime.Title.ByLanguage(titleLanguage)
+	}
+
+	return &CalendarEvent{
+		UID:         fmt.Sprintf("%s-%d@notify.moe", anime.ID, episode.Number),
+		Summary:     fmt.Sprintf("%s - Episode %d", title, episode.Number),
+		Description: description,
+		URL:         link,
+		Start:       start,
+		End:         end,
+	}
+}
+
+// escapeCalendarText escapes the special characters of iCalendar text values.
+func escapeCalendarText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// writeCalendarLine writes a content line followed by CRLF.
+// Long lines are folded without splitting multi-byte characters.
+func writeCalendarLine(buffer *bytes.Buffer, line string) {
+	limit := calendarLineLength
+
+	for len(line) > limit {
+		cut := limit
+
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		buffer.WriteString(line[:cut])
+		buffer.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation lines start with a space
+		limit = calendarLineLength - 1
+	}
+
+	buffer.WriteString(line)
+	buffer.WriteString("\r\n")
+}
@@ -0,0 +1,173 @@
+package arn_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// assertGolden compares the output with the golden file in testdata.
+func assertGolden(t *testing.T, name string, output []byte) {
+	path := filepath.Join("testdata", name)
+
+	if *updateGolden {
+		err := ioutil.WriteFile(path, output, 0644)
+		assert.NoError(t, err)
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(output))
+}
+
+func newTestCalendarEpisodes() []*arn.UpcomingEpisode {
+	steinsGate := &arn.Anime{
+		HasID:         arn.HasID{ID: "steins-gate"},
+		Title:         &arn.AnimeTitle{Canonical: "Steins;Gate", English: "Steins;Gate", Japanese: "シュタインズ・ゲート"},
+		EpisodeLength: 24,
+	}
+
+	madeInAbyss := &arn.Anime{
+		HasID: arn.HasID{ID: "made-in-abyss"},
+		Title: &arn.AnimeTitle{Canonical: "Made in Abyss", Japanese: "メイドインアビス"},
+	}
+
+	return []*arn.UpcomingEpisode{
+		{
+			Anime: steinsGate,
+			Episode: &arn.AnimeEpisode{
+				Number:     2,
+				Title:      arn.EpisodeTitle{English: "Time Travel Paranoia", Japanese: "時間跳躍のパラノイア"},
+				AiringDate: arn.AiringDate{Start: "2011-04-13T15:30:00Z"},
+			},
+		},
+		{
+			Anime: steinsGate,
+			Episode: &arn.AnimeEpisode{
+				Number:     1,
+				Title:      arn.EpisodeTitle{English: "Turning Point", Japanese: "始まりと終わりのプロローグ"},
+				AiringDate: arn.AiringDate{Start: "2011-04-07T00:30:00+09:00", End: "2011-04-07T01:00:00+09:00"},
+			},
+		},
+		{
+			Anime: madeInAbyss,
+			Episode: &arn.AnimeEpisode{
+				Number:     5,
+				Title:      arn.EpisodeTitle{Romaji: "Ooketsu no Fuchi", English: "Incinerator; the edge of the \"Great Fault\", which is very, very deep and dangerous"},
+				AiringDate: arn.AiringDate{Start: "2011-04-08T13:30:00Z"},
+			},
+		},
+		{
+			// No valid airing date
+			Anime: madeInAbyss,
+			Episode: &arn.AnimeEpisode{
+				Number:     6,
+				AiringDate: arn.AiringDate{Start: "2011-04"},
+			},
+		},
+		{
+			// Aired too long ago
+			Anime: madeInAbyss,
+			Episode: &arn.AnimeEpisode{
+				Number:     1,
+				AiringDate: arn.AiringDate{Start: "2011-03-01T13:30:00Z"},
+			},
+		},
+	}
+}
+
+func TestCalendarFeedICS(t *testing.T) {
+	now := time.Date(2011, 4, 6, 12, 0, 0, 0, time.UTC)
+	user := &arn.User{Nick: "Okabe", Location: &arn.Location{TimeZone: "Asia/Tokyo"}}
+	settings := &arn.Settings{TitleLanguage: arn.TitleLanguageCanonical}
+
+	feed := arn.NewCalendarFeed(user, settings, newTestCalendarEpisodes(), now)
+	assert.Len(t, feed.Events, 3)
+	assertGolden(t, "calendar.ics", feed.ICS())
+
+	// Titles in the preferred language
+	settings.TitleLanguage = arn.TitleLanguageEnglish
+	feed = arn.NewCalendarFeed(user, settings, newTestCalendarEpisodes(), now)
+	assertGolden(t, "calendar-english.ics", feed.ICS())
+
+	settings.TitleLanguage = arn.TitleLanguageJapanese
+	feed = arn.NewCalendarFeed(user, settings, newTestCalendarEpisodes(), now)
+	assertGolden(t, "calendar-japanese.ics", feed.ICS())
+}
+
+func TestCalendarFeedEmpty(t *testing.T) {
+	now := time.Date(2011, 4, 6, 12, 0, 0, 0, time.UTC)
+	user := &arn.User{Nick: "Mayuri", Location: &arn.Location{TimeZone: "+09:00"}}
+	settings := &arn.Settings{TitleLanguage: arn.TitleLanguageCanonical}
+
+	feed := arn.NewCalendarFeed(user, settings, nil, now)
+	assertGolden(t, "calendar-empty.ics", feed.ICS())
+
+	// Offsets are used as fixed time zones
+	_, offset := now.In(feed.TimeZone).Zone()
+	assert.Equal(t, 9*60*60, offset)
+}
+
+func TestCalendarFeedLineFolding(t *testing.T) {
+	now := time.Date(2011, 4, 6, 12, 0, 0, 0, time.UTC)
+	user := &arn.User{Nick: "Okabe"}
+	settings := &arn.Settings{TitleLanguage: arn.TitleLanguageEnglish}
+	ics := string(arn.NewCalendarFeed(user, settings, newTestCalendarEpisodes(), now).ICS())
+
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		assert.True(t, len(line) <= 75, line)
+		assert.True(t, utf8.ValidString(line), line)
+	}
+}
+
+func TestCalendarEpisodes(t *testing.T) {
+	now := time.Date(2011, 4, 10, 12, 0, 0, 0, time.UTC)
+	user := &arn.User{HasID: arn.HasID{ID: "calendar-test-user"}, Nick: "Test"}
+	settings := arn.NewSettings(user)
+
+	watching := &arn.Anime{HasID: arn.HasID{ID: "calendar-test-watching"}, Status: "current"}
+	airing := &arn.Anime{HasID: arn.HasID{ID: "calendar-test-airing"}, Status: "current"}
+	list := &arn.AnimeList{
+		UserID: user.ID,
+		Items:  []*arn.AnimeListItem{{AnimeID: watching.ID, Status: arn.AnimeListStatusWatching}},
+	}
+
+	for _, anime := range []*arn.Anime{watching, airing} {
+		arn.DB.Set("Anime", anime.ID, anime)
+		arn.DB.Set("AnimeEpisodes", anime.ID, &arn.AnimeEpisodes{
+			AnimeID: anime.ID,
+			Items:   []*arn.AnimeEpisode{{Number: 1, AiringDate: arn.AiringDate{Start: "2011-04-13T15:30:00Z"}}},
+		})
+
+		defer arn.DB.Delete("Anime", anime.ID)
+		defer arn.DB.Delete("AnimeEpisodes", anime.ID)
+	}
+
+	arn.DB.Set("AnimeList", list.UserID, list)
+	defer arn.DB.Delete("AnimeList", list.UserID)
+
+	// Only the anime on the list are included by default
+	episodes := user.CalendarEpisodes(settings, now)
+	assert.Len(t, episodes, 1)
+	assert.Equal(t, watching.ID, episodes[0].Anime.ID)
+
+	// All airing anime are included after opting in
+	settings.Calendar.FeedAllAnime = true
+	animeIDs := []string{}
+
+	for _, episode := range user.CalendarEpisodes(settings, now) {
+		animeIDs = append(animeIDs, episode.Anime.ID)
+	}
+
+	assert.Contains(t, animeIDs, watching.ID)
+	assert.Contains(t, animeIDs, airing.ID)
+}
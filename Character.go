@@ -186,6 +186,7 @@ func (character *Character) Merge(target *Character) {
 
 	// Delete character
 	DB.Delete("Character", character.ID)
+	notifyDelete("Character", character.ID)
 }
 
 // DeleteImages deletes all images for the character.
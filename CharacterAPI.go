@@ -141,10 +141,12 @@ func (character *Character) Delete() error {
 
 	// Delete character
 	DB.Delete("Character", character.ID)
+	notifyDelete("Character", character.ID)
 	return nil
 }
 
 // Save saves the character in the database.
 func (character *Character) Save() {
 	DB.Set("Character", character.ID, character)
+	notifySave("Character", character)
 }
@@ -74,6 +74,7 @@ func (company *Company) OnRemove(ctx *aero.Context, key string, index int, obj i
 // Save saves the company in the database.
 func (company *Company) Save() {
 	DB.Set("Company", company.ID, company)
+	notifySave("Company", company)
 }
 
 // DeleteInContext deletes the company in the given context.
@@ -120,6 +121,7 @@ func (company *Company) Delete() error {
 	}
 
 	DB.Delete("Company", company.ID)
+	notifyDelete("Company", company.ID)
 	return nil
 }
 
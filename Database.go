@@ -21,12 +21,14 @@ var DB = Node.Namespace("arn").RegisterTypes(
 	(*AnimeEpisodes)(nil),
 	(*AnimeRelations)(nil),
 	(*AnimeList)(nil),
+	(*AnimeRecommendations)(nil),
 	(*Character)(nil),
 	(*ClientErrorReport)(nil),
 	(*Company)(nil),
 	(*DraftIndex)(nil),
 	(*EditLogEntry)(nil),
 	(*EmailToUser)(nil),
+	(*EpisodeDelay)(nil),
 	(*FacebookToUser)(nil),
 	(*GoogleToUser)(nil),
 	(*Group)(nil),
@@ -46,10 +48,14 @@ var DB = Node.Namespace("arn").RegisterTypes(
 	(*ShopItem)(nil),
 	(*SoundTrack)(nil),
 	(*Thread)(nil),
+	(*TrendingSnapshot)(nil),
 	(*TwitterToUser)(nil),
 	(*User)(nil),
+	(*UserCompatibilities)(nil),
 	(*UserFollows)(nil),
 	(*UserNotifications)(nil),
+	(*WatchLog)(nil),
+	(*YearInReviewAverages)(nil),
 )
 
 // MAL is the client for the MyAnimeList database.
@@ -0,0 +1,49 @@
+package arn
+
+import "sync"
+
+// DatabaseListener receives a notification whenever an object is saved or deleted.
+type DatabaseListener interface {
+	OnSave(typeName string, obj interface{})
+	OnDelete(typeName string, id string)
+}
+
+// databaseListeners holds all registered listeners.
+var databaseListeners struct {
+	sync.Mutex
+	value []DatabaseListener
+}
+
+// AddDatabaseListener registers a listener for save and delete events.
+func AddDatabaseListener(listener DatabaseListener) {
+	databaseListeners.Lock()
+	defer databaseListeners.Unlock()
+
+	databaseListeners.value = append(databaseListeners.value, listener)
+}
+
+// notifySave informs all listeners that the object has been saved.
+func notifySave(typeName string, obj interface{}) {
+	for _, listener := range listeners() {
+		listener.OnSave(typeName, obj)
+	}
+}
+
+// notifyDelete informs all listeners that the object has been deleted.
+func notifyDelete(typeName string, id string) {
+	for _, listener := range listeners() {
+		listener.OnDelete(typeName, id)
+	}
+}
+
+// listeners returns a copy of the registered listeners.
+// Listeners are called without holding the lock so that they can take their time
+// and don't block other saves or the registration of new listeners.
+func listeners() []DatabaseListener {
+	databaseListeners.Lock()
+	defer databaseListeners.Unlock()
+
+	copied := make([]DatabaseListener, len(databaseListeners.value))
+	copy(copied, databaseListeners.value)
+	return copied
+}
@@ -0,0 +1,231 @@
+package arn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aerogo/nano"
+)
+
+// EpisodeDelayThreshold is the minimum change of an airing date that counts as a schedule change.
+// Smaller changes are usually corrections of a few minutes by the data source.
+const EpisodeDelayThreshold = 10 * time.Minute
+
+// EpisodeDelay records that the airing date of an episode has been changed by a data source.
+// Episodes moved to an earlier date are recorded as negative delays.
+type EpisodeDelay struct {
+	ID            string `json:"id"`
+	AnimeID       string `json:"animeId"`
+	EpisodeNumber int    `json:"episodeNumber"`
+	OldStart      string `json:"oldStart"`
+	NewStart      string `json:"newStart"`
+	Source        string `json:"source"`
+	Created       string `json:"created"`
+}
+
+// NewEpisodeDelay creates a new episode delay.
+func NewEpisodeDelay(animeID string, episodeNumber int, oldStart string, newStart string, source string) *EpisodeDelay {
+	return &EpisodeDelay{
+		ID:            GenerateID("EpisodeDelay"),
+		AnimeID:       animeID,
+		EpisodeNumber: episodeNumber,
+		OldStart:      oldStart,
+		NewStart:      newStart,
+		Source:        source,
+		Created:       DateTimeUTC(),
+	}
+}
+
+// DetectEpisodeDelays compares the old airing dates with the new episodes and returns
+// the schedule changes of episodes that hadn't aired yet at the given time.
+// Only confirmed airing dates are compared, changes of estimates and dates without a source are expected.
+// The sources map episode numbers to the name of the provider that changed the airing date.
+func DetectEpisodeDelays(animeID string, oldAiringDates map[int]AiringDate, episodes []*AnimeEpisode, sources map[int]string, now time.Time) []*EpisodeDelay {
+	var delays []*EpisodeDelay
+
+	for _, episode := range episodes {
+		old, exists := oldAiringDates[episode.Number]
+
+		if !exists || !old.Confirmed() || !episode.AiringDate.Confirmed() {
+			continue
+		}
+
+		oldStart, _ := time.Parse(time.RFC3339, old.Start)
+		newStart, _ := time.Parse(time.RFC3339, episode.AiringDate.Start)
+
+		if !oldStart.After(now) {
+			continue
+		}
+
+		change := newStart.Sub(oldStart)
+
+		if change < EpisodeDelayThreshold && change > -EpisodeDelayThreshold {
+			continue
+		}
+
+		delays = append(delays, NewEpisodeDelay(animeID, episode.Number, old.Start, episode.AiringDate.Start, sources[episode.Number]))
+	}
+
+	sort.Slice(delays, func(i, j int) bool {
+		return delays[i].EpisodeNumber < delays[j].EpisodeNumber
+	})
+
+	return delays
+}
+
+// Delay returns how much later the episode airs. Negative values mean it airs earlier.
+func (delay *EpisodeDelay) Delay() time.Duration {
+	oldStart, _ := time.Parse(time.RFC3339, delay.OldStart)
+	newStart, _ := time.Parse(time.RFC3339, delay.NewStart)
+	return newStart.Sub(oldStart)
+}
+
+// Anime returns the anime the episode belongs to.
+func (delay *EpisodeDelay) Anime() *Anime {
+	anime, _ := GetAnime(delay.AnimeID)
+	return anime
+}
+
+// Message returns the notification text with the shift and the new airing date in the given time zone.
+func (delay *EpisodeDelay) Message(timeZone *time.Location) string {
+	airing := AiringDate{Start: delay.NewStart}
+	date := airing.StartTime(timeZone).Format("Mon, 02 Jan 15:04 MST")
+	shift := delay.Delay()
+
+	if shift < 0 {
+		return fmt.Sprintf("Episode %d has been moved %s earlier to %s.", delay.EpisodeNumber, formatAiringShift(-shift), date)
+	}
+
+	return fmt.Sprintf("Episode %d has been delayed by %s to %s.", delay.EpisodeNumber, formatAiringShift(shift), date)
+}
+
+// EpisodeDelaysMessage returns a single notification text for the schedule changes of an anime.
+// It names the first affected episode and mentions how many other episodes are affected.
+func EpisodeDelaysMessage(delays []*EpisodeDelay, timeZone *time.Location) string {
+	first := delays[0]
+
+	for _, delay := range delays[1:] {
+		if delay.EpisodeNumber < first.EpisodeNumber {
+			first = delay
+		}
+	}
+
+	message := first.Message(timeZone)
+
+	switch len(delays) {
+	case 1:
+	case 2:
+		message += " 1 more episode has been rescheduled."
+	default:
+		message += fmt.Sprintf(" %d more episodes have been rescheduled.", len(delays)-1)
+	}
+
+	return message
+}
+
+// EpisodeDelaysPushNotification returns a single notification for all schedule changes of the anime.
+func EpisodeDelaysPushNotification(anime *Anime, delays []*EpisodeDelay, user *User) *PushNotification {
+	return &PushNotification{
+		Title:   anime.Title.ByUser(user),
+		Message: EpisodeDelaysMessage(delays, user.TimeZone()),
+		Icon:    anime.ImageLink("medium"),
+		Link:    "https://notify.moe" + anime.Link() + "/episodes",
+		Type:    NotificationTypeAnimeEpisodeDelay,
+	}
+}
+
+// formatAiringShift returns the duration in days, hours and minutes, e.g. "1 day 2 hours".
+func formatAiringShift(duration time.Duration) string {
+	units := []struct {
+		name   string
+		length time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+
+	var parts []string
+
+	for _, unit := range units {
+		count := int(duration / unit.length)
+		duration -= time.Duration(count) * unit.length
+
+		switch count {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+unit.name)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %ss", count, unit.name))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// GetID returns the ID.
+func (delay *EpisodeDelay) GetID() string {
+	return delay.ID
+}
+
+// TypeName returns the type name.
+func (delay *EpisodeDelay) TypeName() string {
+	return "EpisodeDelay"
+}
+
+// Save saves the episode delay in the database.
+func (delay *EpisodeDelay) Save() {
+	DB.Set("EpisodeDelay", delay.ID, delay)
+}
+
+// GetEpisodeDelay returns the episode delay with the given ID.
+func GetEpisodeDelay(id string) (*EpisodeDelay, error) {
+	obj, err := DB.Get("EpisodeDelay", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*EpisodeDelay), nil
+}
+
+// StreamEpisodeDelays returns a stream of all episode delays.
+func StreamEpisodeDelays() chan *EpisodeDelay {
+	channel := make(chan *EpisodeDelay, nano.ChannelBufferSize)
+
+	go func() {
+		for obj := range DB.All("EpisodeDelay") {
+			channel <- obj.(*EpisodeDelay)
+		}
+
+		close(channel)
+	}()
+
+	return channel
+}
+
+// AllEpisodeDelays returns a slice of all episode delays.
+func AllEpisodeDelays() []*EpisodeDelay {
+	var all []*EpisodeDelay
+
+	for obj := range StreamEpisodeDelays() {
+		all = append(all, obj)
+	}
+
+	return all
+}
+
+// FilterEpisodeDelays filters all episode delays by a custom function.
+func FilterEpisodeDelays(filter func(*EpisodeDelay) bool) []*EpisodeDelay {
+	var filtered []*EpisodeDelay
+
+	for obj := range StreamEpisodeDelays() {
+		if filter(obj) {
+			filtered = append(filtered, obj)
+		}
+	}
+
+	return filtered
+}
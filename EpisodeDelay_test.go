@@ -0,0 +1,135 @@
+package arn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnimeUpdateEpisodesDelays(t *testing.T) {
+	anime := &arn.Anime{HasID: arn.HasID{ID: "steins-gate"}, EpisodeLength: 24}
+
+	episodes := &arn.AnimeEpisodes{
+		AnimeID: anime.ID,
+		Items: []*arn.AnimeEpisode{
+			newTestEpisode(1, "", "2019-04-06T01:05:00+09:00", "", nil),
+			newTestEpisode(2, "", "2019-04-13T01:05:00+09:00", "", nil),
+			newTestEpisode(3, "", "2019-04-20T01:05:00+09:00", "", nil),
+			newTestEpisode(4, "", "2019-04-27T01:05:00+09:00", "", nil),
+		},
+	}
+
+	// Episode 4 has only been estimated
+	episodes.Items[3].AiringDate.Estimated = true
+
+	schedule := &fakeEpisodeProvider{
+		name: "schedule",
+		episodes: []*arn.AnimeEpisode{
+			// Already aired, corrected date
+			newTestEpisode(1, "", "2019-04-06T02:05:00+09:00", "", nil),
+
+			// Small correction
+			newTestEpisode(2, "", "2019-04-13T01:10:00+09:00", "", nil),
+
+			// Recap week
+			newTestEpisode(3, "", "2019-04-27T01:05:00+09:00", "", nil),
+			newTestEpisode(4, "", "2019-05-04T01:05:00+09:00", "", nil),
+		},
+	}
+
+	registry := arn.NewEpisodeProviderRegistry()
+	registry.Register(schedule, 10, arn.EpisodeFieldAiringDate)
+
+	now := time.Date(2019, 4, 10, 0, 0, 0, 0, time.UTC)
+	delays, errs := anime.UpdateEpisodes(episodes, registry, now)
+	assert.Empty(t, errs)
+	assert.Len(t, delays, 1)
+
+	delay := delays[0]
+	assert.Equal(t, anime.ID, delay.AnimeID)
+	assert.Equal(t, 3, delay.EpisodeNumber)
+	assert.Equal(t, "2019-04-20T01:05:00+09:00", delay.OldStart)
+	assert.Equal(t, "2019-04-27T01:05:00+09:00", delay.NewStart)
+	assert.Equal(t, "schedule", delay.Source)
+	assert.Equal(t, 7*24*time.Hour, delay.Delay())
+
+	// Nothing changed
+	delays, _ = anime.UpdateEpisodes(episodes, registry, now)
+	assert.Empty(t, delays)
+}
+
+func TestAnimeUpdateEpisodesWithoutSource(t *testing.T) {
+	anime := &arn.Anime{HasID: arn.HasID{ID: "steins-gate"}, EpisodeLength: 24}
+
+	// Airing date saved before sources were recorded
+	episodes := &arn.AnimeEpisodes{
+		AnimeID: anime.ID,
+		Items: []*arn.AnimeEpisode{
+			newTestEpisode(1, "", "2019-04-20T01:05:00+09:00", "", nil),
+		},
+	}
+
+	episodes.Items[0].AiringDate.Source = ""
+
+	schedule := &fakeEpisodeProvider{
+		name: "schedule",
+		episodes: []*arn.AnimeEpisode{
+			newTestEpisode(1, "", "2019-04-27T01:05:00+09:00", "", nil),
+		},
+	}
+
+	registry := arn.NewEpisodeProviderRegistry()
+	registry.Register(schedule, 10, arn.EpisodeFieldAiringDate)
+
+	now := time.Date(2019, 4, 10, 0, 0, 0, 0, time.UTC)
+	delays, errs := anime.UpdateEpisodes(episodes, registry, now)
+	assert.Empty(t, errs)
+	assert.Empty(t, delays)
+	assert.Equal(t, "schedule", episodes.Items[0].AiringDate.Source)
+}
+
+func TestEpisodeDelayMessage(t *testing.T) {
+	tokyo, ok := arn.ParseTimeZone("Asia/Tokyo")
+	assert.True(t, ok)
+
+	delay := arn.NewEpisodeDelay("steins-gate", 3, "2019-04-19T16:05:00Z", "2019-04-26T16:05:00Z", "shoboi")
+	assert.Equal(t, "Episode 3 has been delayed by 7 days to Sat, 27 Apr 01:05 JST.", delay.Message(tokyo))
+	assert.Equal(t, "Episode 3 has been delayed by 7 days to Fri, 26 Apr 16:05 UTC.", delay.Message(time.UTC))
+
+	delay = arn.NewEpisodeDelay("steins-gate", 3, "2019-04-26T16:05:00Z", "2019-04-19T14:35:00Z", "shoboi")
+	assert.Equal(t, "Episode 3 has been moved 7 days 1 hour 30 minutes earlier to Fri, 19 Apr 23:35 JST.", delay.Message(tokyo))
+}
+
+func TestEpisodeDelaysMessage(t *testing.T) {
+	tokyo, ok := arn.ParseTimeZone("Asia/Tokyo")
+	assert.True(t, ok)
+
+	delays := []*arn.EpisodeDelay{
+		arn.NewEpisodeDelay("steins-gate", 4, "2019-04-26T16:05:00Z", "2019-05-03T16:05:00Z", "shoboi"),
+		arn.NewEpisodeDelay("steins-gate", 3, "2019-04-19T16:05:00Z", "2019-04-26T16:05:00Z", "shoboi"),
+		arn.NewEpisodeDelay("steins-gate", 5, "2019-05-03T16:05:00Z", "2019-05-10T16:05:00Z", "shoboi"),
+	}
+
+	// One message for all episodes, named after the first one
+	assert.Equal(t, "Episode 3 has been delayed by 7 days to Sat, 27 Apr 01:05 JST. 2 more episodes have been rescheduled.", arn.EpisodeDelaysMessage(delays, tokyo))
+	assert.Equal(t, "Episode 3 has been delayed by 7 days to Sat, 27 Apr 01:05 JST. 1 more episode has been rescheduled.", arn.EpisodeDelaysMessage(delays[:2], tokyo))
+	assert.Equal(t, "Episode 3 has been delayed by 7 days to Sat, 27 Apr 01:05 JST.", arn.EpisodeDelaysMessage(delays[1:2], tokyo))
+}
+
+func TestEpisodeDelayNotificationSettings(t *testing.T) {
+	assert.True(t, arn.DefaultNotificationSettings().AnimeEpisodeDelays)
+
+	// Settings saved before the option existed are notified
+	settings := &arn.Settings{}
+	err := jsoniter.Unmarshal([]byte(`{"notification":{"newFollowers":false}}`), settings)
+	assert.NoError(t, err)
+	assert.True(t, settings.Notification.AnimeEpisodeDelays)
+	assert.False(t, settings.Notification.NewFollowers)
+
+	err = jsoniter.Unmarshal([]byte(`{"notification":{"animeEpisodeDelays":false}}`), settings)
+	assert.NoError(t, err)
+	assert.False(t, settings.Notification.AnimeEpisodeDelays)
+}
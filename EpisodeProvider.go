@@ -0,0 +1,159 @@
+package arn
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/animenotifier/arn/validate"
+)
+
+// EpisodeProviders is the registry of episode providers used by RefreshEpisodes.
+var EpisodeProviders = NewEpisodeProviderRegistry()
+
+// Register the built-in episode providers.
+func init() {
+	EpisodeProviders.Register(&ShoboiEpisodeProvider{}, 20, EpisodeFieldTitle, EpisodeFieldAiringDate)
+	EpisodeProviders.Register(&TwistEpisodeProvider{}, 10, EpisodeFieldLinks)
+}
+
+// EpisodeProvider is a source of episode information.
+type EpisodeProvider interface {
+	// Name returns the unique name of the provider.
+	Name() string
+
+	// Episodes returns the episodes of the anime.
+	Episodes(anime *Anime) ([]*AnimeEpisode, error)
+}
+
+// RegisteredEpisodeProvider is an episode provider with its priority and trusted fields.
+type RegisteredEpisodeProvider struct {
+	Provider EpisodeProvider
+	Priority int
+	Fields   []string
+}
+
+// EpisodeProviderRegistry contains the episode providers that are queried for episode information.
+type EpisodeProviderRegistry struct {
+	providers []*RegisteredEpisodeProvider
+	sync.Mutex
+}
+
+// NewEpisodeProviderRegistry creates an empty registry.
+func NewEpisodeProviderRegistry() *EpisodeProviderRegistry {
+	return &EpisodeProviderRegistry{}
+}
+
+// Register adds the provider to the registry or replaces the provider with the same name.
+// Only the given fields are taken from the provider's episodes. If several providers
+// are trusted with the same field, the provider with the higher priority wins.
+func (registry *EpisodeProviderRegistry) Register(provider EpisodeProvider, priority int, fields ...string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registered := &RegisteredEpisodeProvider{
+		Provider: provider,
+		Priority: priority,
+		Fields:   fields,
+	}
+
+	for index, existing := range registry.providers {
+		if existing.Provider.Name() == provider.Name() {
+			registry.providers[index] = registered
+			return
+		}
+	}
+
+	registry.providers = append(registry.providers, registered)
+}
+
+// Unregister removes the provider with the given name.
+func (registry *EpisodeProviderRegistry) Unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for index, existing := range registry.providers {
+		if existing.Provider.Name() == name {
+			registry.providers = append(registry.providers[:index], registry.providers[index+1:]...)
+			return
+		}
+	}
+}
+
+// Providers returns the registered providers sorted by priority, highest priority first.
+func (registry *EpisodeProviderRegistry) Providers() []*RegisteredEpisodeProvider {
+	registry.Lock()
+	providers := make([]*RegisteredEpisodeProvider, len(registry.providers))
+	copy(providers, registry.providers)
+	registry.Unlock()
+
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].Priority > providers[j].Priority
+	})
+
+	return providers
+}
+
+// Merge queries all providers and merges their episodes into the episode list.
+// Providers with lower priority are merged first so that higher priorities overwrite them.
+// Returns the names of the providers that changed airing dates, mapped by episode number.
+// Errors of single providers don't stop the other providers and are returned at the end.
+func (registry *EpisodeProviderRegistry) Merge(anime *Anime, episodes *AnimeEpisodes) (map[int]string, []error) {
+	var errs []error
+	sources := map[int]string{}
+	providers := registry.Providers()
+
+	for i := len(providers) - 1; i >= 0; i-- {
+		registered := providers[i]
+		providerEpisodes, err := registered.Provider.Episodes(anime)
+
+		if err != nil {
+			errs = append(errs, errors.New(registered.Provider.Name()+": "+err.Error()))
+			continue
+		}
+
+		// Confirmed dates remember the provider they came from
+		for _, episode := range providerEpisodes {
+			if !episode.AiringDate.Estimated && validate.DateTime(episode.AiringDate.Start) {
+				episode.AiringDate.Source = registered.Provider.Name()
+			}
+		}
+
+		before := episodes.AiringDates()
+		episodes.MergeFields(providerEpisodes, registered.Fields...)
+
+		for number, airingDate := range episodes.AiringDates() {
+			if airingDate.Start != before[number].Start {
+				sources[number] = registered.Provider.Name()
+			}
+		}
+	}
+
+	return sources, errs
+}
+
+// ShoboiEpisodeProvider provides the Japanese titles and airing dates from cal.syoboi.jp.
+type ShoboiEpisodeProvider struct{}
+
+// Name returns the provider name.
+func (provider *ShoboiEpisodeProvider) Name() string {
+	return "shoboi"
+}
+
+// Episodes returns the episodes of the anime.
+func (provider *ShoboiEpisodeProvider) Episodes(anime *Anime) ([]*AnimeEpisode, error) {
+	return anime.ShoboiEpisodes()
+}
+
+// TwistEpisodeProvider provides the episode links from twist.moe.
+type TwistEpisodeProvider struct{}
+
+// Name returns the provider name.
+func (provider *TwistEpisodeProvider) Name() string {
+	return "twist.moe"
+}
+
+// Episodes returns the episodes of the anime.
+func (provider *TwistEpisodeProvider) Episodes(anime *Anime) ([]*AnimeEpisode, error) {
+	return anime.TwistEpisodes()
+}
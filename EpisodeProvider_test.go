@@ -0,0 +1,175 @@
+package arn_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEpisodeProvider returns a fixed list of episodes.
+type fakeEpisodeProvider struct {
+	name     string
+	episodes []*arn.AnimeEpisode
+	err      error
+}
+
+func (provider *fakeEpisodeProvider) Name() string {
+	return provider.name
+}
+
+func (provider *fakeEpisodeProvider) Episodes(anime *arn.Anime) ([]*arn.AnimeEpisode, error) {
+	return provider.episodes, provider.err
+}
+
+func newTestEpisode(number int, japaneseTitle string, start string, end string, links map[string]string) *arn.AnimeEpisode {
+	episode := arn.NewAnimeEpisode()
+	episode.Number = number
+	episode.Title.Japanese = japaneseTitle
+	episode.AiringDate.Start = start
+	episode.AiringDate.End = end
+
+	if start != "" {
+		episode.AiringDate.Source = "test"
+	}
+
+	if links != nil {
+		episode.Links = links
+	}
+
+	return episode
+}
+
+func TestEpisodeProviderRegistry(t *testing.T) {
+	registry := arn.NewEpisodeProviderRegistry()
+	registry.Register(&fakeEpisodeProvider{name: "low"}, 1)
+	registry.Register(&fakeEpisodeProvider{name: "high"}, 10)
+	registry.Register(&fakeEpisodeProvider{name: "medium"}, 5)
+
+	providers := registry.Providers()
+	assert.Len(t, providers, 3)
+	assert.Equal(t, "high", providers[0].Provider.Name())
+	assert.Equal(t, "medium", providers[1].Provider.Name())
+	assert.Equal(t, "low", providers[2].Provider.Name())
+
+	// Registering the same name replaces the provider
+	registry.Register(&fakeEpisodeProvider{name: "low"}, 20)
+	providers = registry.Providers()
+	assert.Len(t, providers, 3)
+	assert.Equal(t, "low", providers[0].Provider.Name())
+
+	registry.Unregister("medium")
+	assert.Len(t, registry.Providers(), 2)
+
+	// Built-in providers
+	assert.Len(t, arn.EpisodeProviders.Providers(), 2)
+}
+
+func TestAnimeUpdateEpisodes(t *testing.T) {
+	anime := &arn.Anime{HasID: arn.HasID{ID: "steins-gate"}}
+
+	// Trusted for airing dates, delivers episodes in a different order
+	schedule := &fakeEpisodeProvider{
+		name: "schedule",
+		episodes: []*arn.AnimeEpisode{
+			newTestEpisode(2, "Wrong title", "2011-04-13T15:30:00Z", "2011-04-13T16:00:00Z", nil),
+			newTestEpisode(1, "Wrong title", "2011-04-06T15:30:00Z", "2011-04-06T16:00:00Z", nil),
+		},
+	}
+
+	// Trusted for titles, knows about a third episode
+	titles := &fakeEpisodeProvider{
+		name: "titles",
+		episodes: []*arn.AnimeEpisode{
+			newTestEpisode(1, "始まりと終わりのプロローグ", "2000-01-01T00:00:00Z", "", nil),
+			newTestEpisode(3, "並列過程のパラノイア", "", "", nil),
+		},
+	}
+
+	// Trusted for links
+	stream := &fakeEpisodeProvider{
+		name: "stream",
+		episodes: []*arn.AnimeEpisode{
+			newTestEpisode(2, "", "", "", map[string]string{"stream": "https://example.com/2"}),
+		},
+	}
+
+	broken := &fakeEpisodeProvider{
+		name: "broken",
+		err:  errors.New("Service unavailable"),
+	}
+
+	registry := arn.NewEpisodeProviderRegistry()
+	registry.Register(schedule, 20, arn.EpisodeFieldAiringDate)
+	registry.Register(titles, 10, arn.EpisodeFieldTitle, arn.EpisodeFieldAiringDate)
+	registry.Register(stream, 5, arn.EpisodeFieldLinks)
+	registry.Register(broken, 1, arn.EpisodeFields...)
+
+	// Existing episodes keep their data
+	episodes := &arn.AnimeEpisodes{
+		AnimeID: anime.ID,
+		Items: []*arn.AnimeEpisode{
+			newTestEpisode(1, "", "", "", map[string]string{"old": "https://example.com/1"}),
+		},
+	}
+
+	_, errs := anime.UpdateEpisodes(episodes, registry, time.Now())
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "broken")
+
+	assert.Len(t, episodes.Items, 3)
+
+	for index, episode := range episodes.Items {
+		assert.Equal(t, index+1, episode.Number)
+	}
+
+	first := episodes.Items[0]
+	assert.Equal(t, "始まりと終わりのプロローグ", first.Title.Japanese)
+	assert.Equal(t, "2011-04-06T15:30:00Z", first.AiringDate.Start)
+	assert.Equal(t, "https://example.com/1", first.Links["old"])
+
+	second := episodes.Items[1]
+	assert.Equal(t, "", second.Title.Japanese)
+	assert.Equal(t, "2011-04-13T15:30:00Z", second.AiringDate.Start)
+	assert.Equal(t, "https://example.com/2", second.Links["stream"])
+
+	// Airing date of the third episode is guessed
+	third := episodes.Items[2]
+	assert.Equal(t, "並列過程のパラノイア", third.Title.Japanese)
+	assert.Equal(t, "2011-04-20T15:30:00Z", third.AiringDate.Start)
+}
+
+func TestAnimeEpisodesMergeUnnumbered(t *testing.T) {
+	episodes := &arn.AnimeEpisodes{
+		Items: []*arn.AnimeEpisode{
+			newTestEpisode(1, "", "", "", nil),
+			newTestEpisode(2, "", "", "", nil),
+		},
+	}
+
+	// Episodes without a number are matched by position
+	unnumbered := []*arn.AnimeEpisode{
+		newTestEpisode(-1, "", "", "", map[string]string{"stream": "https://example.com/1"}),
+		newTestEpisode(-1, "", "", "", map[string]string{"stream": "https://example.com/2"}),
+	}
+
+	for i := 0; i < 2; i++ {
+		episodes.MergeFields(unnumbered, arn.EpisodeFieldLinks)
+		assert.Len(t, episodes.Items, 2)
+	}
+
+	assert.Equal(t, "https://example.com/1", episodes.Items[0].Links["stream"])
+	assert.Equal(t, "https://example.com/2", episodes.Items[1].Links["stream"])
+
+	// The episode numbers are kept
+	assert.Equal(t, 1, episodes.Items[0].Number)
+	assert.Equal(t, 2, episodes.Items[1].Number)
+
+	// Numbered episodes are still matched afterwards
+	episodes.MergeFields(unnumbered[:1], arn.EpisodeFieldLinks)
+	episodes.MergeFields([]*arn.AnimeEpisode{newTestEpisode(1, "", "", "", nil)}, arn.EpisodeFieldLinks)
+	assert.Len(t, episodes.Items, 2)
+	assert.Equal(t, 1, episodes.Items[0].Number)
+}
@@ -49,3 +49,13 @@ func (obj *HasMappings) RemoveMapping(name string) bool {
 
 	return false
 }
+
+// GetMappings returns all external mappings.
+func (obj *HasMappings) GetMappings() []*Mapping {
+	return obj.Mappings
+}
+
+// RemoveMappingAt removes the mapping at the given index.
+func (obj *HasMappings) RemoveMappingAt(index int) {
+	obj.Mappings = append(obj.Mappings[:index], obj.Mappings[index+1:]...)
+}
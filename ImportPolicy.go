@@ -0,0 +1,197 @@
+package arn
+
+import "errors"
+
+// Names of the import policies
+const (
+	ImportPolicyMerge        = "merge"
+	ImportPolicyPreferRemote = "prefer-remote"
+	ImportPolicyPreferLocal  = "prefer-local"
+	ImportPolicyNewest       = "newest"
+	ImportPolicyFieldByField = "field-by-field"
+)
+
+// Sides of an import, the existing list item is local and the imported one is remote
+const (
+	ImportSideLocal  = "local"
+	ImportSideRemote = "remote"
+)
+
+// importFields are the fields that an import policy decides on.
+var importFields = []string{
+	ImportFieldStatus,
+	ImportFieldEpisodes,
+	ImportFieldRating,
+	ImportFieldNotes,
+	ImportFieldRewatchCount,
+}
+
+// ImportPolicy decides which value is kept when an imported item
+// and an existing list item for the same anime differ.
+type ImportPolicy interface {
+	Name() string
+	Resolve(field string, local *AnimeListItem, remote *AnimeListItem) string
+}
+
+// NewImportPolicy returns the policy with the given name.
+// The fields map the field names to ImportSideLocal or ImportSideRemote
+// and are only used by the field-by-field policy.
+func NewImportPolicy(name string, fields map[string]string) (ImportPolicy, error) {
+	switch name {
+	case ImportPolicyMerge, "":
+		return &MergeImportPolicy{}, nil
+	case ImportPolicyPreferRemote:
+		return &PreferRemoteImportPolicy{}, nil
+	case ImportPolicyPreferLocal:
+		return &PreferLocalImportPolicy{}, nil
+	case ImportPolicyNewest:
+		return &NewestImportPolicy{}, nil
+	case ImportPolicyFieldByField:
+		for field, side := range fields {
+			if !Contains(importFields, field) {
+				return nil, errors.New("Unknown import field: " + field)
+			}
+
+			if side != ImportSideLocal && side != ImportSideRemote {
+				return nil, errors.New("Invalid import side for " + field + ": " + side)
+			}
+		}
+
+		return &FieldByFieldImportPolicy{Fields: fields}, nil
+	default:
+		return nil, errors.New("Unknown import policy: " + name)
+	}
+}
+
+// MergeImportPolicy is the default policy: The status is always imported,
+// episodes and rewatch count are only increased and rating and notes are only filled if empty.
+type MergeImportPolicy struct{}
+
+// Name returns the name of the policy.
+func (policy *MergeImportPolicy) Name() string {
+	return ImportPolicyMerge
+}
+
+// Resolve returns the side whose value is kept.
+func (policy *MergeImportPolicy) Resolve(field string, local *AnimeListItem, remote *AnimeListItem) string {
+	switch field {
+	case ImportFieldEpisodes:
+		return sideIf(remote.Episodes > local.Episodes)
+	case ImportFieldRating:
+		return sideIf(local.Rating.Overall == 0)
+	case ImportFieldNotes:
+		return sideIf(local.Notes == "")
+	case ImportFieldRewatchCount:
+		return sideIf(remote.RewatchCount > local.RewatchCount)
+	default:
+		return ImportSideRemote
+	}
+}
+
+// PreferRemoteImportPolicy treats the external service as authoritative.
+// All fields supplied by the service are overwritten, even if the imported value is empty.
+type PreferRemoteImportPolicy struct{}
+
+// Name returns the name of the policy.
+func (policy *PreferRemoteImportPolicy) Name() string {
+	return ImportPolicyPreferRemote
+}
+
+// Resolve returns the side whose value is kept.
+func (policy *PreferRemoteImportPolicy) Resolve(field string, local *AnimeListItem, remote *AnimeListItem) string {
+	return ImportSideRemote
+}
+
+// PreferLocalImportPolicy never modifies existing list items,
+// only anime that are not in the list yet are added.
+type PreferLocalImportPolicy struct{}
+
+// Name returns the name of the policy.
+func (policy *PreferLocalImportPolicy) Name() string {
+	return ImportPolicyPreferLocal
+}
+
+// Resolve returns the side whose value is kept.
+func (policy *PreferLocalImportPolicy) Resolve(field string, local *AnimeListItem, remote *AnimeListItem) string {
+	return ImportSideLocal
+}
+
+// NewestImportPolicy keeps all fields of the item that has been edited last.
+// Items without an edit date are considered older than items with one.
+type NewestImportPolicy struct{}
+
+// Name returns the name of the policy.
+func (policy *NewestImportPolicy) Name() string {
+	return ImportPolicyNewest
+}
+
+// Resolve returns the side whose value is kept.
+func (policy *NewestImportPolicy) Resolve(field string, local *AnimeListItem, remote *AnimeListItem) string {
+	// Edit dates are stored as RFC 3339 in UTC and can be compared as strings.
+	return sideIf(remote.Edited > local.Edited)
+}
+
+// FieldByFieldImportPolicy lets the user choose the side for every field.
+// Fields without a choice use the merge policy.
+type FieldByFieldImportPolicy struct {
+	Fields map[string]string
+}
+
+// Name returns the name of the policy.
+func (policy *FieldByFieldImportPolicy) Name() string {
+	return ImportPolicyFieldByField
+}
+
+// Resolve returns the side whose value is kept.
+func (policy *FieldByFieldImportPolicy) Resolve(field string, local *AnimeListItem, remote *AnimeListItem) string {
+	side, exists := policy.Fields[field]
+
+	if !exists {
+		return (&MergeImportPolicy{}).Resolve(field, local, remote)
+	}
+
+	return side
+}
+
+// suppliedFieldsImportPolicy keeps the existing value of the fields
+// that the import source doesn't supply and uses the policy for all others.
+type suppliedFieldsImportPolicy struct {
+	ImportPolicy
+	fields []string
+}
+
+// Resolve returns the side whose value is kept.
+func (policy *suppliedFieldsImportPolicy) Resolve(field string, local *AnimeListItem, remote *AnimeListItem) string {
+	if !Contains(policy.fields, field) {
+		return ImportSideLocal
+	}
+
+	return policy.ImportPolicy.Resolve(field, local, remote)
+}
+
+// sideIf returns the remote side if the condition is true and the local side otherwise.
+func sideIf(remote bool) string {
+	if remote {
+		return ImportSideRemote
+	}
+
+	return ImportSideLocal
+}
+
+// importFieldValue returns the value of an import field.
+func importFieldValue(item *AnimeListItem, field string) interface{} {
+	switch field {
+	case ImportFieldStatus:
+		return item.Status
+	case ImportFieldEpisodes:
+		return item.Episodes
+	case ImportFieldRating:
+		return item.Rating.Overall
+	case ImportFieldNotes:
+		return item.Notes
+	case ImportFieldRewatchCount:
+		return item.RewatchCount
+	default:
+		return nil
+	}
+}
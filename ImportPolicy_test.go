@@ -0,0 +1,176 @@
+package arn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	"github.com/animenotifier/mal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPolicyList(anime *arn.Anime) (*arn.AnimeList, *arn.AnimeListItem) {
+	item := &arn.AnimeListItem{
+		AnimeID:      anime.ID,
+		Status:       arn.AnimeListStatusWatching,
+		Episodes:     10,
+		Notes:        "Mine",
+		RewatchCount: 1,
+	}
+
+	item.Rating.Overall = 9
+	return newTestImportList(item), item
+}
+
+func TestNewImportPolicy(t *testing.T) {
+	for _, name := range []string{arn.ImportPolicyMerge, arn.ImportPolicyPreferRemote, arn.ImportPolicyPreferLocal, arn.ImportPolicyNewest, arn.ImportPolicyFieldByField} {
+		policy, err := arn.NewImportPolicy(name, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, name, policy.Name())
+	}
+
+	_, err := arn.NewImportPolicy("unknown", nil)
+	assert.Error(t, err)
+
+	_, err = arn.NewImportPolicy(arn.ImportPolicyFieldByField, map[string]string{"created": arn.ImportSideRemote})
+	assert.Error(t, err)
+
+	_, err = arn.NewImportPolicy(arn.ImportPolicyFieldByField, map[string]string{arn.ImportFieldNotes: "both"})
+	assert.Error(t, err)
+}
+
+func TestImportPolicyPreferRemote(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list, _ := newTestPolicyList(anime)
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "PAUSED", 4, 0, "", 0),
+	}, &arn.PreferRemoteImportPolicy{})
+
+	assert.Len(t, plan.Updated, 1)
+	assert.Empty(t, plan.Conflicts)
+
+	item := plan.Updated[0].Item
+	assert.Equal(t, arn.AnimeListStatusHold, item.Status)
+	assert.Equal(t, 4, item.Episodes)
+	assert.Equal(t, 0.0, item.Rating.Overall)
+	assert.Equal(t, "", item.Notes)
+	assert.Equal(t, 0, item.RewatchCount)
+}
+
+func TestImportPolicyPreferRemoteMyAnimeList(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list, _ := newTestPolicyList(anime)
+	plan := arn.PlanMyAnimeListImport(list, []*arn.MyAnimeListMatch{
+		{
+			MyAnimeListItem: &mal.AnimeListItem{
+				AnimeID:            31043,
+				Status:             mal.AnimeListStatusHold,
+				NumWatchedEpisodes: 4,
+			},
+			ARNAnime: anime,
+		},
+	}, &arn.PreferRemoteImportPolicy{})
+
+	assert.Len(t, plan.Updated, 1)
+	assert.Empty(t, plan.Conflicts)
+
+	// MyAnimeList entries have no notes and rewatch count
+	item := plan.Updated[0].Item
+	assert.Equal(t, arn.AnimeListStatusHold, item.Status)
+	assert.Equal(t, 4, item.Episodes)
+	assert.Equal(t, 0.0, item.Rating.Overall)
+	assert.Equal(t, "Mine", item.Notes)
+	assert.Equal(t, 1, item.RewatchCount)
+}
+
+func TestImportPolicyPreferLocal(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	added := newTestImportAnime("clannad")
+	list, _ := newTestPolicyList(anime)
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "PAUSED", 12, 50, "Theirs", 3),
+		newTestImportMatch(added, "PLANNING", 0, 0, "", 0),
+	}, &arn.PreferLocalImportPolicy{})
+
+	assert.Empty(t, plan.Updated)
+	assert.Len(t, plan.Added, 1)
+	assert.Len(t, plan.Conflicts, 5)
+}
+
+func TestImportPolicyNewest(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list, item := newTestPolicyList(anime)
+	edited, _ := time.Parse(time.RFC3339, item.Edited)
+
+	// Remote entry has been edited after the local one
+	match := newTestImportMatch(anime, "PAUSED", 4, 50, "Theirs", 0)
+	match.AniListItem.UpdatedAt = int(edited.Add(time.Hour).Unix())
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{match}, &arn.NewestImportPolicy{})
+
+	assert.Len(t, plan.Updated, 1)
+	assert.Equal(t, arn.AnimeListStatusHold, plan.Updated[0].Item.Status)
+	assert.Equal(t, 4, plan.Updated[0].Item.Episodes)
+	assert.Equal(t, "Theirs", plan.Updated[0].Item.Notes)
+
+	// Remote entry is older than the local one
+	match.AniListItem.UpdatedAt = int(edited.Add(-time.Hour).Unix())
+	plan = arn.PlanAniListImport(list, []*arn.AniListMatch{match}, &arn.NewestImportPolicy{})
+
+	assert.Empty(t, plan.Updated)
+	assert.NotEmpty(t, plan.Conflicts)
+}
+
+func TestImportPolicyNewestMyAnimeList(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list, _ := newTestPolicyList(anime)
+	match := &arn.MyAnimeListMatch{
+		MyAnimeListItem: &mal.AnimeListItem{
+			AnimeID:            31043,
+			Status:             mal.AnimeListStatusHold,
+			NumWatchedEpisodes: 4,
+			Score:              5,
+		},
+		ARNAnime: anime,
+	}
+
+	// MyAnimeList entries have no edit date and are older than the local item
+	assert.Empty(t, match.AnimeListItem().Edited)
+	plan := arn.PlanMyAnimeListImport(list, []*arn.MyAnimeListMatch{match}, &arn.NewestImportPolicy{})
+
+	assert.Empty(t, plan.Updated)
+	assert.NotEmpty(t, plan.Conflicts)
+
+	// Added items are edited at the time of the import
+	list.Items = nil
+	plan = arn.PlanMyAnimeListImport(list, []*arn.MyAnimeListMatch{match}, &arn.NewestImportPolicy{})
+	assert.NoError(t, plan.Apply(list))
+	assert.NotEmpty(t, list.Items[0].Edited)
+	arn.DB.Delete("WatchLog", list.UserID)
+}
+
+func TestImportPolicyFieldByField(t *testing.T) {
+	anime := newTestImportAnime("erased")
+	list, _ := newTestPolicyList(anime)
+	policy, err := arn.NewImportPolicy(arn.ImportPolicyFieldByField, map[string]string{
+		arn.ImportFieldStatus: arn.ImportSideLocal,
+		arn.ImportFieldRating: arn.ImportSideRemote,
+	})
+
+	assert.NoError(t, err)
+
+	plan := arn.PlanAniListImport(list, []*arn.AniListMatch{
+		newTestImportMatch(anime, "PAUSED", 12, 50, "Theirs", 0),
+	}, policy)
+
+	assert.Len(t, plan.Updated, 1)
+
+	// Chosen fields
+	item := plan.Updated[0].Item
+	assert.Equal(t, arn.AnimeListStatusWatching, item.Status)
+	assert.Equal(t, 5.0, item.Rating.Overall)
+
+	// Merge rules for the remaining fields
+	assert.Equal(t, 12, item.Episodes)
+	assert.Equal(t, "Mine", item.Notes)
+	assert.Equal(t, 1, item.RewatchCount)
+}
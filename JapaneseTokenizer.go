@@ -1,8 +1,17 @@
 package arn
 
-import "github.com/animenotifier/japanese/client"
+import (
+	"github.com/animenotifier/japanese"
+	"github.com/animenotifier/japanese/client"
+)
+
+// Tokenizer splits a Japanese sentence into tokens.
+type Tokenizer interface {
+	Tokenize(sentence string) []*japanese.Token
+}
 
 // JapaneseTokenizer tokenizes a sentence via the HTTP API.
-var JapaneseTokenizer = &client.Tokenizer{
+// It can be replaced by a local implementation, e.g. in tests.
+var JapaneseTokenizer Tokenizer = &client.Tokenizer{
 	Endpoint: "http://arn-jp:1234/",
 }
@@ -1,6 +1,8 @@
 package arn
 
 import (
+	"time"
+
 	"github.com/animenotifier/kitsu"
 	jsoniter "github.com/json-iterator/go"
 )
@@ -17,3 +19,40 @@ func (match *KitsuMatch) JSON() string {
 	PanicOnError(err)
 	return string(b)
 }
+
+// ImportFields returns the anime list item fields that Kitsu entries supply.
+func (match *KitsuMatch) ImportFields() []string {
+	return importFields
+}
+
+// AnimeListItem converts the Kitsu library entry to an anime list item.
+// Kitsu ratings use a 0-20 scale.
+// Entries without an update date have no edit date so that they count as older than edited items.
+func (match *KitsuMatch) AnimeListItem() *AnimeListItem {
+	now := time.Now().UTC()
+	attributes := &match.KitsuItem.Attributes
+	created := now
+	edited := ""
+
+	if !attributes.CreatedAt.IsZero() {
+		created = attributes.CreatedAt.UTC()
+	}
+
+	if !attributes.UpdatedAt.IsZero() {
+		edited = attributes.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+
+	return &AnimeListItem{
+		AnimeID:  match.ARNAnime.ID,
+		Status:   KitsuStatusToARNStatus(attributes.Status),
+		Episodes: attributes.Progress,
+		Rating: AnimeListItemRating{
+			Overall: float64(attributes.RatingTwenty) / 2,
+		},
+		Notes:        attributes.Notes,
+		RewatchCount: attributes.ReconsumeCount,
+		Private:      attributes.Private,
+		Created:      created.Format(time.RFC3339),
+		Edited:       edited,
+	}
+}
@@ -0,0 +1,223 @@
+package arn
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/animenotifier/kitsu"
+)
+
+// KitsuSyncProtection is the time span in which fields edited by humans
+// are not overwritten by the Kitsu sync.
+const KitsuSyncProtection = 90 * 24 * time.Hour
+
+// kitsuSyncKeys maps the difference types to the edit log keys of the anime fields.
+var kitsuSyncKeys = map[string]string{
+	AnimeDifferenceCanonicalTitle: "Title.Canonical",
+	AnimeDifferenceEnglishTitle:   "Title.English",
+	AnimeDifferenceJapaneseTitle:  "Title.Japanese",
+	AnimeDifferenceSynopsis:       "Summary",
+	AnimeDifferenceEpisodeCount:   "EpisodeCount",
+	AnimeDifferenceStartDate:      "StartDate",
+	AnimeDifferenceEndDate:        "EndDate",
+	AnimeDifferenceStatus:         "Status",
+}
+
+// KitsuSyncOptions configures which fields may be changed by the Kitsu sync.
+type KitsuSyncOptions struct {
+	// ProtectedKeys are the edit log keys of the fields that must not be changed,
+	// mapped by anime ID.
+	ProtectedKeys map[string]map[string]bool
+
+	// IsIgnored reports whether editors decided to ignore the difference.
+	IsIgnored func(difference *AnimeDifference) bool
+}
+
+// NewKitsuSyncOptions creates the options for a sync at the given time.
+// It reads the edit log once, so create it once for all anime that are synced together.
+func NewKitsuSyncOptions(now time.Time) *KitsuSyncOptions {
+	return &KitsuSyncOptions{
+		ProtectedKeys: RecentlyEditedAnimeKeys(now.Add(-KitsuSyncProtection)),
+		IsIgnored:     (*AnimeDifference).IsIgnored,
+	}
+}
+
+// SyncAllAnimeFromKitsu syncs all anime that have a Kitsu mapping.
+// Errors of single anime don't stop the sync and are returned at the end.
+func SyncAllAnimeFromKitsu() ([]*EditLogEntry, []error) {
+	var all []*EditLogEntry
+	var errs []error
+	options := NewKitsuSyncOptions(time.Now())
+
+	for anime := range StreamAnime() {
+		if anime.GetMapping("kitsu/anime") == "" {
+			continue
+		}
+
+		entries, err := anime.SyncFromKitsu(options)
+
+		if err != nil {
+			errs = append(errs, errors.New(anime.ID+": "+err.Error()))
+			continue
+		}
+
+		all = append(all, entries...)
+	}
+
+	return all, errs
+}
+
+// SyncFromKitsu updates the anime field by field with the data in the Kitsu database.
+// Fields that have been edited by humans recently and ignored differences are left untouched.
+// Every change is saved as an edit log entry of the bot user.
+func (anime *Anime) SyncFromKitsu(options *KitsuSyncOptions) ([]*EditLogEntry, error) {
+	kitsuID := anime.GetMapping("kitsu/anime")
+
+	if kitsuID == "" {
+		return nil, errors.New("Anime " + anime.ID + " has no Kitsu mapping")
+	}
+
+	obj, err := Kitsu.Get("Anime", kitsuID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := SyncAnimeFromKitsu(anime, obj.(*kitsu.Anime), options)
+
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	anime.Save()
+
+	for _, entry := range entries {
+		entry.Save()
+	}
+
+	return entries, nil
+}
+
+// SyncAnimeFromKitsu applies the Kitsu data to the anime and returns
+// the edit log entries for the changes. Neither the anime nor the entries are saved.
+func SyncAnimeFromKitsu(anime *Anime, kitsuAnime *kitsu.Anime, options *KitsuSyncOptions) []*EditLogEntry {
+	var entries []*EditLogEntry
+	protectedKeys := options.ProtectedKeys[anime.ID]
+
+	for _, difference := range DiffKitsuAnime(anime, kitsuAnime) {
+		key, exists := kitsuSyncKeys[difference.TypeName]
+
+		if !exists || protectedKeys[key] {
+			continue
+		}
+
+		if options.IsIgnored != nil && options.IsIgnored(difference) {
+			continue
+		}
+
+		oldValue, newValue, ok := anime.applyKitsuDifference(difference)
+
+		if !ok {
+			continue
+		}
+
+		entries = append(entries, NewEditLogEntry(BotUserID, "edit", "Anime", anime.ID, key, oldValue, newValue))
+	}
+
+	return entries
+}
+
+// RecentlyEditedAnimeKeys returns the keys of the anime fields that have been edited
+// by humans since the given time, mapped by anime ID.
+func RecentlyEditedAnimeKeys(since time.Time) map[string]map[string]bool {
+	keysByAnime := map[string]map[string]bool{}
+	sinceString := since.UTC().Format(time.RFC3339)
+
+	for entry := range StreamEditLogEntries() {
+		if entry.ObjectType != "Anime" || entry.Action != "edit" {
+			continue
+		}
+
+		if entry.UserID == BotUserID || entry.Created < sinceString {
+			continue
+		}
+
+		keys, exists := keysByAnime[entry.ObjectID]
+
+		if !exists {
+			keys = map[string]bool{}
+			keysByAnime[entry.ObjectID] = keys
+		}
+
+		keys[entry.Key] = true
+	}
+
+	return keysByAnime
+}
+
+// applyKitsuDifference sets the field to the value suggested by Kitsu
+// and returns the old and the new value. Returns false if the value can't be applied.
+func (anime *Anime) applyKitsuDifference(difference *AnimeDifference) (string, string, bool) {
+	value := difference.Remote
+
+	if anime.Title == nil {
+		anime.Title = &AnimeTitle{}
+	}
+
+	switch difference.TypeName {
+	case AnimeDifferenceCanonicalTitle:
+		oldValue := anime.Title.Canonical
+		anime.Title.Canonical = value
+		return oldValue, value, true
+
+	case AnimeDifferenceEnglishTitle:
+		oldValue := anime.Title.English
+		anime.Title.English = value
+		return oldValue, value, true
+
+	case AnimeDifferenceJapaneseTitle:
+		oldValue := anime.Title.Japanese
+		anime.Title.Japanese = value
+		return oldValue, value, true
+
+	case AnimeDifferenceSynopsis:
+		oldValue := anime.Summary
+		anime.Summary = value
+		return oldValue, value, true
+
+	case AnimeDifferenceEpisodeCount:
+		episodeCount, err := strconv.Atoi(value)
+
+		if err != nil {
+			return "", "", false
+		}
+
+		oldValue := strconv.Itoa(anime.EpisodeCount)
+		anime.EpisodeCount = episodeCount
+		return oldValue, value, true
+
+	case AnimeDifferenceStartDate:
+		oldValue := anime.StartDate
+		anime.StartDate = value
+		return oldValue, value, true
+
+	case AnimeDifferenceEndDate:
+		oldValue := anime.EndDate
+		anime.EndDate = value
+		return oldValue, value, true
+
+	case AnimeDifferenceStatus:
+		// Status "unreleased" means the same as "upcoming" so we should normalize it
+		if value == "unreleased" {
+			value = "upcoming"
+		}
+
+		oldValue := anime.Status
+		anime.Status = value
+		return oldValue, value, true
+
+	default:
+		return "", "", false
+	}
+}
@@ -0,0 +1,78 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/animenotifier/kitsu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncAnimeFromKitsu(t *testing.T) {
+	anime := newTestDiffAnime()
+	anime.Status = "current"
+	anime.EndDate = ""
+
+	kitsuAnime := &kitsu.Anime{ID: "5646"}
+	kitsuAnime.Attributes.CanonicalTitle = "Steins;Gate"
+	kitsuAnime.Attributes.Titles.JaJp = "STEINS;GATE"
+	kitsuAnime.Attributes.EpisodeCount = 25
+	kitsuAnime.Attributes.EndDate = "2011-09-14"
+	kitsuAnime.Attributes.Status = "finished"
+
+	options := &arn.KitsuSyncOptions{
+		// A human editor recently changed the Japanese title
+		ProtectedKeys: map[string]map[string]bool{
+			anime.ID: {"Title.Japanese": true},
+		},
+
+		// An editor decided that the episode count is correct
+		IsIgnored: func(difference *arn.AnimeDifference) bool {
+			return difference.TypeName == arn.AnimeDifferenceEpisodeCount
+		},
+	}
+
+	entries := arn.SyncAnimeFromKitsu(anime, kitsuAnime, options)
+	assert.Len(t, entries, 2)
+
+	assert.Equal(t, "シュタインズ・ゲート", anime.Title.Japanese)
+	assert.Equal(t, 24, anime.EpisodeCount)
+	assert.Equal(t, "2011-09-14", anime.EndDate)
+	assert.Equal(t, "finished", anime.Status)
+
+	for _, entry := range entries {
+		assert.Equal(t, arn.BotUserID, entry.UserID)
+		assert.Equal(t, "edit", entry.Action)
+		assert.Equal(t, "Anime", entry.ObjectType)
+		assert.Equal(t, anime.ID, entry.ObjectID)
+	}
+
+	assert.Equal(t, "EndDate", entries[0].Key)
+	assert.Equal(t, "", entries[0].OldValue)
+	assert.Equal(t, "2011-09-14", entries[0].NewValue)
+
+	assert.Equal(t, "Status", entries[1].Key)
+	assert.Equal(t, "current", entries[1].OldValue)
+	assert.Equal(t, "finished", entries[1].NewValue)
+
+	// Nothing left to sync
+	assert.Empty(t, arn.SyncAnimeFromKitsu(anime, kitsuAnime, options))
+}
+
+func TestSyncAnimeFromKitsuStatus(t *testing.T) {
+	anime := newTestDiffAnime()
+	anime.EpisodeCount = 0
+
+	kitsuAnime := &kitsu.Anime{ID: "5646"}
+	kitsuAnime.Attributes.EpisodeCount = 24
+	kitsuAnime.Attributes.Status = "unreleased"
+
+	entries := arn.SyncAnimeFromKitsu(anime, kitsuAnime, &arn.KitsuSyncOptions{})
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 24, anime.EpisodeCount)
+	assert.Equal(t, "0", entries[0].OldValue)
+
+	// Kitsu's "unreleased" is saved as "upcoming"
+	assert.Equal(t, "upcoming", anime.Status)
+	assert.Equal(t, "upcoming", entries[1].NewValue)
+}
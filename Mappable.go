@@ -0,0 +1,13 @@
+package arn
+
+// Mappable applies to any type that has mappings to external services.
+type Mappable interface {
+	GetID() string
+	TypeName() string
+	GetMapping(name string) string
+	GetMappings() []*Mapping
+	SetMapping(serviceName string, serviceID string)
+	RemoveMapping(name string) bool
+	RemoveMappingAt(index int)
+	Save()
+}
@@ -0,0 +1,380 @@
+package arn
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Kinds of mapping audit issues
+const (
+	// MappingIssueDuplicate means that several objects claim the same external ID.
+	MappingIssueDuplicate = MappingConflictDuplicate
+
+	// MappingIssueMultiple means that one object has several IDs for the same service.
+	MappingIssueMultiple = MappingConflictMultiple
+
+	// MappingIssueOrphan means that the external ID doesn't exist in the external database.
+	MappingIssueOrphan = "orphan"
+
+	// MappingIssueInconsistent means that an external database reports a different ID for a service.
+	MappingIssueInconsistent = "inconsistent"
+)
+
+// MappingAuditOptions configures the checks of a mapping audit.
+type MappingAuditOptions struct {
+	// ProposeFixes adds fix proposals to the issues that can be fixed automatically.
+	ProposeFixes bool
+
+	// Exists reports whether the external ID exists in the external database.
+	// The second return value is false if the service can't be checked.
+	Exists func(service string, serviceID string) (exists bool, checked bool)
+
+	// CrossReferences returns the IDs of other services that the external database
+	// reports for the given external ID, mapped by service name.
+	CrossReferences func(service string, serviceID string) map[string]string
+}
+
+// MappingAudit is the result of a mapping integrity check.
+type MappingAudit struct {
+	Issues []*MappingAuditIssue `json:"issues"`
+}
+
+// MappingAuditIssue is a single problem found by the audit.
+type MappingAuditIssue struct {
+	Kind        string        `json:"kind"`
+	TypeName    string        `json:"typeName"`
+	ObjectIDs   []string      `json:"objectIds"`
+	Service     string        `json:"service"`
+	ServiceIDs  []string      `json:"serviceIds"`
+	Description string        `json:"description"`
+	Fixes       []*MappingFix `json:"fixes"`
+}
+
+// MappingFix is a proposed change of a single mapping.
+// An empty new ID removes the mapping.
+type MappingFix struct {
+	TypeName     string `json:"typeName"`
+	ObjectID     string `json:"objectId"`
+	Service      string `json:"service"`
+	OldServiceID string `json:"oldServiceId"`
+	NewServiceID string `json:"newServiceId"`
+}
+
+// AuditMappings checks the mappings of all objects in the resolver.
+func AuditMappings(resolver *MappingResolver, options *MappingAuditOptions) *MappingAudit {
+	audit := &MappingAudit{
+		Issues: []*MappingAuditIssue{},
+	}
+
+	orphans := map[MappingFix]bool{}
+
+	// Orphans
+	if options.Exists != nil {
+		for _, obj := range resolver.Objects() {
+			for _, mapping := range obj.GetMappings() {
+				exists, checked := options.Exists(mapping.Service, mapping.ServiceID)
+
+				if !checked || exists {
+					continue
+				}
+
+				fix := MappingFix{
+					TypeName:     obj.TypeName(),
+					ObjectID:     obj.GetID(),
+					Service:      mapping.Service,
+					OldServiceID: mapping.ServiceID,
+				}
+
+				if orphans[fix] {
+					continue
+				}
+
+				orphans[fix] = true
+				issue := newMappingAuditIssue(MappingIssueOrphan, obj, mapping.Service, mapping.ServiceID)
+				issue.Description = fmt.Sprintf("%s %s doesn't exist", mapping.Service, mapping.ServiceID)
+				issue.Fixes = []*MappingFix{&fix}
+				audit.Issues = append(audit.Issues, issue)
+			}
+		}
+	}
+
+	// Duplicates and multiple IDs for one service
+	for _, conflict := range resolver.Conflicts() {
+		issue := &MappingAuditIssue{
+			Kind:       conflict.Kind,
+			TypeName:   conflict.TypeName,
+			ObjectIDs:  conflict.ObjectIDs,
+			Service:    conflict.Service,
+			ServiceIDs: conflict.ServiceIDs,
+		}
+
+		switch conflict.Kind {
+		case MappingConflictDuplicate:
+			issue.Description = fmt.Sprintf("%d objects claim %s %s", len(conflict.ObjectIDs), conflict.Service, conflict.ServiceIDs[0])
+			issue.Fixes = duplicateMappingFixes(resolver, conflict, options)
+		case MappingConflictMultiple:
+			issue.Description = fmt.Sprintf("%d different IDs for %s", len(conflict.ServiceIDs), conflict.Service)
+		}
+
+		audit.Issues = append(audit.Issues, issue)
+	}
+
+	// Cross-service inconsistencies
+	if options.CrossReferences != nil {
+		for _, obj := range resolver.Objects() {
+			for _, mapping := range obj.GetMappings() {
+				for service, expectedID := range options.CrossReferences(mapping.Service, mapping.ServiceID) {
+					actualID := obj.GetMapping(service)
+
+					if actualID == "" || actualID == expectedID {
+						continue
+					}
+
+					issue := newMappingAuditIssue(MappingIssueInconsistent, obj, service, actualID)
+					issue.Description = fmt.Sprintf("%s %s reports %s %s", mapping.Service, mapping.ServiceID, service, expectedID)
+					issue.Fixes = []*MappingFix{
+						{
+							TypeName:     obj.TypeName(),
+							ObjectID:     obj.GetID(),
+							Service:      service,
+							OldServiceID: actualID,
+							NewServiceID: expectedID,
+						},
+					}
+
+					audit.Issues = append(audit.Issues, issue)
+				}
+			}
+		}
+	}
+
+	if !options.ProposeFixes {
+		for _, issue := range audit.Issues {
+			issue.Fixes = nil
+		}
+	}
+
+	sort.SliceStable(audit.Issues, func(i, j int) bool {
+		a := audit.Issues[i]
+		b := audit.Issues[j]
+
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+
+		return a.ObjectIDs[0] < b.ObjectIDs[0]
+	})
+
+	return audit
+}
+
+// RunMappingAudit audits all anime, characters and companies in the database.
+// Orphans are checked against the MAL and Kitsu databases
+// and cross references are taken from the Kitsu mappings.
+func RunMappingAudit(proposeFixes bool) *MappingAudit {
+	return AuditMappings(NewDatabaseMappingResolver(), &MappingAuditOptions{
+		ProposeFixes:    proposeFixes,
+		Exists:          ExternalMappingExists,
+		CrossReferences: NewKitsuCrossReferences(),
+	})
+}
+
+// ExternalMappingExists checks whether the external ID exists in the MAL and Kitsu databases.
+// Other services can't be checked.
+func ExternalMappingExists(service string, serviceID string) (exists bool, checked bool) {
+	var err error
+
+	switch service {
+	case "myanimelist/anime":
+		_, err = MAL.Get("Anime", serviceID)
+	case "myanimelist/character":
+		_, err = MAL.Get("Character", serviceID)
+	case "kitsu/anime":
+		_, err = Kitsu.Get("Anime", serviceID)
+	case "kitsu/character":
+		_, err = Kitsu.Get("Character", serviceID)
+	default:
+		return false, false
+	}
+
+	return err == nil, true
+}
+
+// NewKitsuCrossReferences returns the cross references for Kitsu anime
+// based on the mappings in the Kitsu database.
+func NewKitsuCrossReferences() func(service string, serviceID string) map[string]string {
+	references := map[string]map[string]string{}
+
+	for mapping := range StreamKitsuMappings() {
+		item := mapping.Relationships.Item.Data
+
+		if item.Type != "anime" {
+			continue
+		}
+
+		service, serviceID, _ := KitsuMappingService(mapping)
+
+		if service == "" || serviceID == "" {
+			continue
+		}
+
+		if references[item.ID] == nil {
+			references[item.ID] = map[string]string{}
+		}
+
+		references[item.ID][service] = serviceID
+	}
+
+	return func(service string, serviceID string) map[string]string {
+		if service != "kitsu/anime" {
+			return nil
+		}
+
+		return references[serviceID]
+	}
+}
+
+// Fixes returns all proposed fixes of the audit.
+// If several issues propose to change the same mapping, only the first proposal is returned.
+func (audit *MappingAudit) Fixes() []*MappingFix {
+	var fixes []*MappingFix
+	proposed := map[MappingFix]bool{}
+
+	for _, issue := range audit.Issues {
+		for _, fix := range issue.Fixes {
+			key := *fix
+			key.NewServiceID = ""
+
+			if proposed[key] {
+				continue
+			}
+
+			proposed[key] = true
+			fixes = append(fixes, fix)
+		}
+	}
+
+	return fixes
+}
+
+// ApplyFixes applies all proposed fixes to the objects in the resolver,
+// saves the modified objects and logs the changes in the name of the given user.
+// On errors, the log entries of the fixes applied so far are returned.
+func (audit *MappingAudit) ApplyFixes(resolver *MappingResolver, userID string) ([]*EditLogEntry, error) {
+	var entries []*EditLogEntry
+
+	for _, fix := range audit.Fixes() {
+		obj := resolver.Get(fix.TypeName, fix.ObjectID)
+
+		if obj == nil {
+			return entries, errors.New("Object not found: " + fix.TypeName + " " + fix.ObjectID)
+		}
+
+		entry, err := fix.Apply(obj, userID)
+
+		if err != nil {
+			return entries, err
+		}
+
+		obj.Save()
+		entry.Save()
+		resolver.Add(obj)
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Apply changes the mapping of the object and returns the edit log entry for the change.
+// Saving the object and the log entry is the responsibility of the caller.
+func (fix *MappingFix) Apply(obj Mappable, userID string) (*EditLogEntry, error) {
+	if obj.TypeName() != fix.TypeName || obj.GetID() != fix.ObjectID {
+		return nil, errors.New("Mapping fix doesn't belong to " + obj.TypeName() + " " + obj.GetID())
+	}
+
+	for index, mapping := range obj.GetMappings() {
+		if mapping.Service != fix.Service || mapping.ServiceID != fix.OldServiceID {
+			continue
+		}
+
+		// Remove
+		if fix.NewServiceID == "" {
+			removed := fmt.Sprint(mapping)
+			obj.RemoveMappingAt(index)
+			return NewEditLogEntry(userID, "arrayRemove", fix.TypeName, fix.ObjectID, fmt.Sprintf("Mappings[%d]", index), removed, ""), nil
+		}
+
+		// Edit
+		mapping.ServiceID = fix.NewServiceID
+		return NewEditLogEntry(userID, "edit", fix.TypeName, fix.ObjectID, fmt.Sprintf("Mappings[%d].ServiceID", index), fix.OldServiceID, fix.NewServiceID), nil
+	}
+
+	return nil, errors.New("Mapping not found: " + fix.Service + " " + fix.OldServiceID)
+}
+
+// newMappingAuditIssue creates an issue for a single object and external ID.
+func newMappingAuditIssue(kind string, obj Mappable, service string, serviceID string) *MappingAuditIssue {
+	return &MappingAuditIssue{
+		Kind:       kind,
+		TypeName:   obj.TypeName(),
+		ObjectIDs:  []string{obj.GetID()},
+		Service:    service,
+		ServiceIDs: []string{serviceID},
+	}
+}
+
+// duplicateMappingFixes proposes to remove a duplicate external ID from all objects
+// whose other mappings contradict it according to the cross references.
+// If the cross references don't identify exactly one correct object, no fixes are proposed.
+func duplicateMappingFixes(resolver *MappingResolver, conflict *MappingConflict, options *MappingAuditOptions) []*MappingFix {
+	if options.CrossReferences == nil {
+		return nil
+	}
+
+	serviceID := conflict.ServiceIDs[0]
+	var confirmed []Mappable
+	var others []Mappable
+
+	for _, obj := range resolver.FindAll(conflict.Service, serviceID) {
+		if obj.TypeName() != conflict.TypeName {
+			continue
+		}
+
+		isConfirmed := false
+
+		for _, mapping := range obj.GetMappings() {
+			if options.CrossReferences(mapping.Service, mapping.ServiceID)[conflict.Service] == serviceID {
+				isConfirmed = true
+				break
+			}
+		}
+
+		if isConfirmed {
+			confirmed = append(confirmed, obj)
+		} else {
+			others = append(others, obj)
+		}
+	}
+
+	if len(confirmed) != 1 {
+		return nil
+	}
+
+	fixes := make([]*MappingFix, 0, len(others))
+
+	for _, obj := range others {
+		fixes = append(fixes, &MappingFix{
+			TypeName:     obj.TypeName(),
+			ObjectID:     obj.GetID(),
+			Service:      conflict.Service,
+			OldServiceID: serviceID,
+		})
+	}
+
+	return fixes
+}
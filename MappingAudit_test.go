@@ -0,0 +1,137 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAuditOptions() *arn.MappingAuditOptions {
+	return &arn.MappingAuditOptions{
+		ProposeFixes: true,
+		Exists: func(service string, serviceID string) (bool, bool) {
+			if service != "myanimelist/anime" {
+				return false, false
+			}
+
+			return serviceID != "404", true
+		},
+		CrossReferences: func(service string, serviceID string) map[string]string {
+			if service == "kitsu/anime" && serviceID == "11" {
+				return map[string]string{"myanimelist/anime": "1"}
+			}
+
+			if service == "kitsu/anime" && serviceID == "12" {
+				return map[string]string{"myanimelist/anime": "2"}
+			}
+
+			return nil
+		},
+	}
+}
+
+func findAuditIssue(audit *arn.MappingAudit, kind string) *arn.MappingAuditIssue {
+	for _, issue := range audit.Issues {
+		if issue.Kind == kind {
+			return issue
+		}
+	}
+
+	return nil
+}
+
+func TestMappingAudit(t *testing.T) {
+	resolver := arn.NewMappingResolver()
+
+	// "a" and "b" claim the same MAL ID, but Kitsu confirms it only for "a"
+	resolver.Add(newTestMappedAnime("a",
+		&arn.Mapping{Service: "myanimelist/anime", ServiceID: "1"},
+		&arn.Mapping{Service: "kitsu/anime", ServiceID: "11"},
+	))
+
+	resolver.Add(newTestMappedAnime("b",
+		&arn.Mapping{Service: "myanimelist/anime", ServiceID: "1"},
+	))
+
+	// "c" has a MAL ID that Kitsu disagrees with
+	resolver.Add(newTestMappedAnime("c",
+		&arn.Mapping{Service: "myanimelist/anime", ServiceID: "3"},
+		&arn.Mapping{Service: "kitsu/anime", ServiceID: "12"},
+	))
+
+	// "d" has a MAL ID that doesn't exist
+	resolver.Add(newTestMappedAnime("d",
+		&arn.Mapping{Service: "myanimelist/anime", ServiceID: "404"},
+	))
+
+	audit := arn.AuditMappings(resolver, newTestAuditOptions())
+	assert.Len(t, audit.Issues, 3)
+
+	duplicate := findAuditIssue(audit, arn.MappingIssueDuplicate)
+	assert.NotNil(t, duplicate)
+	assert.Equal(t, []string{"a", "b"}, duplicate.ObjectIDs)
+	assert.Len(t, duplicate.Fixes, 1)
+	assert.Equal(t, "b", duplicate.Fixes[0].ObjectID)
+	assert.Equal(t, "", duplicate.Fixes[0].NewServiceID)
+
+	inconsistent := findAuditIssue(audit, arn.MappingIssueInconsistent)
+	assert.NotNil(t, inconsistent)
+	assert.Equal(t, []string{"c"}, inconsistent.ObjectIDs)
+	assert.Equal(t, "3", inconsistent.Fixes[0].OldServiceID)
+	assert.Equal(t, "2", inconsistent.Fixes[0].NewServiceID)
+
+	orphan := findAuditIssue(audit, arn.MappingIssueOrphan)
+	assert.NotNil(t, orphan)
+	assert.Equal(t, []string{"d"}, orphan.ObjectIDs)
+	assert.Equal(t, []string{"404"}, orphan.ServiceIDs)
+
+	assert.Len(t, audit.Fixes(), 3)
+
+	// Reports without fixes
+	options := newTestAuditOptions()
+	options.ProposeFixes = false
+	audit = arn.AuditMappings(resolver, options)
+	assert.Len(t, audit.Issues, 3)
+	assert.Empty(t, audit.Fixes())
+}
+
+func TestMappingFixApply(t *testing.T) {
+	anime := newTestMappedAnime("c",
+		&arn.Mapping{Service: "myanimelist/anime", ServiceID: "3"},
+		&arn.Mapping{Service: "anidb/anime", ServiceID: "404"},
+	)
+
+	edit := &arn.MappingFix{
+		TypeName:     "Anime",
+		ObjectID:     "c",
+		Service:      "myanimelist/anime",
+		OldServiceID: "3",
+		NewServiceID: "2",
+	}
+
+	entry, err := edit.Apply(anime, "4J6qpK1ve")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", anime.GetMapping("myanimelist/anime"))
+	assert.Equal(t, "edit", entry.Action)
+	assert.Equal(t, "Mappings[0].ServiceID", entry.Key)
+	assert.Equal(t, "3", entry.OldValue)
+	assert.Equal(t, "2", entry.NewValue)
+
+	remove := &arn.MappingFix{
+		TypeName:     "Anime",
+		ObjectID:     "c",
+		Service:      "anidb/anime",
+		OldServiceID: "404",
+	}
+
+	entry, err = remove.Apply(anime, "4J6qpK1ve")
+	assert.NoError(t, err)
+	assert.Equal(t, "", anime.GetMapping("anidb/anime"))
+	assert.Equal(t, "arrayRemove", entry.Action)
+	assert.Equal(t, "Mappings[1]", entry.Key)
+
+	// The mapping doesn't exist anymore
+	_, err = remove.Apply(anime, "4J6qpK1ve")
+	assert.Error(t, err)
+}
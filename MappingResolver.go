@@ -0,0 +1,306 @@
+package arn
+
+import (
+	"sort"
+	"sync"
+)
+
+// Kinds of mapping conflicts
+const (
+	// MappingConflictDuplicate means that several objects claim the same external ID.
+	MappingConflictDuplicate = "duplicate"
+
+	// MappingConflictMultiple means that one object has several IDs for the same service.
+	MappingConflictMultiple = "multiple"
+)
+
+// MappingResolver indexes the mappings of objects of any type that has mappings
+// and finds the objects by the ID of any external service.
+// Service names contain the object type, e.g. "kitsu/anime" and "kitsu/character",
+// therefore a single resolver can hold objects of different types.
+type MappingResolver struct {
+	objects  map[string]Mappable
+	services map[string]map[string][]Mappable
+	mutex    sync.RWMutex
+}
+
+// MappingConflict describes a duplicate or ambiguous mapping.
+type MappingConflict struct {
+	Kind       string   `json:"kind"`
+	Service    string   `json:"service"`
+	ServiceIDs []string `json:"serviceIds"`
+	TypeName   string   `json:"typeName"`
+	ObjectIDs  []string `json:"objectIds"`
+}
+
+// NewMappingResolver creates an empty resolver.
+func NewMappingResolver() *MappingResolver {
+	return &MappingResolver{
+		objects:  map[string]Mappable{},
+		services: map[string]map[string][]Mappable{},
+	}
+}
+
+// NewDatabaseMappingResolver creates a resolver for all anime, characters and companies.
+func NewDatabaseMappingResolver() *MappingResolver {
+	resolver := NewMappingResolver()
+
+	for anime := range StreamAnime() {
+		resolver.Add(anime)
+	}
+
+	for character := range StreamCharacters() {
+		resolver.Add(character)
+	}
+
+	for company := range StreamCompanies() {
+		resolver.Add(company)
+	}
+
+	return resolver
+}
+
+// Add indexes all mappings of the object.
+// If the object has already been added, its index entries are updated.
+func (resolver *MappingResolver) Add(obj Mappable) {
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	key := mappableKey(obj)
+	resolver.remove(key)
+	resolver.objects[key] = obj
+	indexed := map[Mapping]bool{}
+
+	for _, mapping := range obj.GetMappings() {
+		if mapping.ServiceID == "" || indexed[*mapping] {
+			continue
+		}
+
+		indexed[*mapping] = true
+
+		ids, exists := resolver.services[mapping.Service]
+
+		if !exists {
+			ids = map[string][]Mappable{}
+			resolver.services[mapping.Service] = ids
+		}
+
+		ids[mapping.ServiceID] = append(ids[mapping.ServiceID], obj)
+	}
+}
+
+// Remove removes the object from the index.
+func (resolver *MappingResolver) Remove(obj Mappable) {
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	resolver.remove(mappableKey(obj))
+}
+
+// remove removes the object with the given key from the index.
+// The caller needs to hold the write lock.
+func (resolver *MappingResolver) remove(key string) {
+	_, exists := resolver.objects[key]
+
+	if !exists {
+		return
+	}
+
+	delete(resolver.objects, key)
+
+	for service, ids := range resolver.services {
+		for serviceID, objects := range ids {
+			remaining := objects[:0]
+
+			for _, obj := range objects {
+				if mappableKey(obj) != key {
+					remaining = append(remaining, obj)
+				}
+			}
+
+			if len(remaining) == 0 {
+				delete(ids, serviceID)
+			} else {
+				ids[serviceID] = remaining
+			}
+		}
+
+		if len(ids) == 0 {
+			delete(resolver.services, service)
+		}
+	}
+}
+
+// Find returns the object with the given external ID.
+// Returns nil if there is no such object or if several objects claim the ID.
+func (resolver *MappingResolver) Find(service string, serviceID string) Mappable {
+	objects := resolver.FindAll(service, serviceID)
+
+	if len(objects) != 1 {
+		return nil
+	}
+
+	return objects[0]
+}
+
+// FindAll returns all objects that claim the given external ID.
+func (resolver *MappingResolver) FindAll(service string, serviceID string) []Mappable {
+	resolver.mutex.RLock()
+	defer resolver.mutex.RUnlock()
+
+	objects := resolver.services[service][serviceID]
+	result := make([]Mappable, len(objects))
+	copy(result, objects)
+	return result
+}
+
+// Get returns the object with the given type name and ID if it has been added.
+func (resolver *MappingResolver) Get(typeName string, id string) Mappable {
+	resolver.mutex.RLock()
+	defer resolver.mutex.RUnlock()
+
+	return resolver.objects[typeName+":"+id]
+}
+
+// FindAnime returns the anime with the given external ID, e.g. "kitsu/anime".
+func (resolver *MappingResolver) FindAnime(service string, serviceID string) *Anime {
+	anime, _ := resolver.Find(service, serviceID).(*Anime)
+	return anime
+}
+
+// FindCharacter returns the character with the given external ID, e.g. "anilist/character".
+func (resolver *MappingResolver) FindCharacter(service string, serviceID string) *Character {
+	character, _ := resolver.Find(service, serviceID).(*Character)
+	return character
+}
+
+// FindCompany returns the company with the given external ID, e.g. "ann/company".
+func (resolver *MappingResolver) FindCompany(service string, serviceID string) *Company {
+	company, _ := resolver.Find(service, serviceID).(*Company)
+	return company
+}
+
+// Services returns the sorted names of all indexed services.
+func (resolver *MappingResolver) Services() []string {
+	resolver.mutex.RLock()
+	defer resolver.mutex.RUnlock()
+
+	services := make([]string, 0, len(resolver.services))
+
+	for service := range resolver.services {
+		services = append(services, service)
+	}
+
+	sort.Strings(services)
+	return services
+}
+
+// Objects returns all indexed objects sorted by type and ID.
+func (resolver *MappingResolver) Objects() []Mappable {
+	resolver.mutex.RLock()
+	defer resolver.mutex.RUnlock()
+
+	keys := make([]string, 0, len(resolver.objects))
+
+	for key := range resolver.objects {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	objects := make([]Mappable, len(keys))
+
+	for index, key := range keys {
+		objects[index] = resolver.objects[key]
+	}
+
+	return objects
+}
+
+// Conflicts returns all external IDs that are claimed by several objects
+// and all objects that have several IDs for the same service.
+func (resolver *MappingResolver) Conflicts() []*MappingConflict {
+	var conflicts []*MappingConflict
+
+	for _, obj := range resolver.Objects() {
+		ids := map[string][]string{}
+
+		for _, mapping := range obj.GetMappings() {
+			if mapping.ServiceID != "" && !Contains(ids[mapping.Service], mapping.ServiceID) {
+				ids[mapping.Service] = append(ids[mapping.Service], mapping.ServiceID)
+			}
+		}
+
+		for service, serviceIDs := range ids {
+			if len(serviceIDs) < 2 {
+				continue
+			}
+
+			sort.Strings(serviceIDs)
+
+			conflicts = append(conflicts, &MappingConflict{
+				Kind:       MappingConflictMultiple,
+				Service:    service,
+				ServiceIDs: serviceIDs,
+				TypeName:   obj.TypeName(),
+				ObjectIDs:  []string{obj.GetID()},
+			})
+		}
+	}
+
+	for _, service := range resolver.Services() {
+		resolver.mutex.RLock()
+		for serviceID, objects := range resolver.services[service] {
+			objectIDs := map[string][]string{}
+
+			for _, obj := range objects {
+				if !Contains(objectIDs[obj.TypeName()], obj.GetID()) {
+					objectIDs[obj.TypeName()] = append(objectIDs[obj.TypeName()], obj.GetID())
+				}
+			}
+
+			for typeName, ids := range objectIDs {
+				if len(ids) < 2 {
+					continue
+				}
+
+				sort.Strings(ids)
+
+				conflicts = append(conflicts, &MappingConflict{
+					Kind:       MappingConflictDuplicate,
+					Service:    service,
+					ServiceIDs: []string{serviceID},
+					TypeName:   typeName,
+					ObjectIDs:  ids,
+				})
+			}
+		}
+
+		resolver.mutex.RUnlock()
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		a := conflicts[i]
+		b := conflicts[j]
+
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+
+		if a.ServiceIDs[0] != b.ServiceIDs[0] {
+			return a.ServiceIDs[0] < b.ServiceIDs[0]
+		}
+
+		return a.ObjectIDs[0] < b.ObjectIDs[0]
+	})
+
+	return conflicts
+}
+
+// mappableKey returns the unique key of the object in the resolver.
+func mappableKey(obj Mappable) string {
+	return obj.TypeName() + ":" + obj.GetID()
+}
@@ -0,0 +1,80 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMappedAnime(id string, mappings ...*arn.Mapping) *arn.Anime {
+	anime := &arn.Anime{HasID: arn.HasID{ID: id}}
+	anime.Mappings = mappings
+	return anime
+}
+
+func TestMappingResolverFind(t *testing.T) {
+	steinsGate := newTestMappedAnime("steins-gate",
+		&arn.Mapping{Service: "myanimelist/anime", ServiceID: "9253"},
+		&arn.Mapping{Service: "kitsu/anime", ServiceID: "5646"},
+		&arn.Mapping{Service: "thetvdb/anime", ServiceID: "244061"},
+	)
+
+	character := &arn.Character{}
+	character.ID = "okabe"
+	character.SetMapping("kitsu/character", "5646")
+
+	resolver := arn.NewMappingResolver()
+	resolver.Add(steinsGate)
+	resolver.Add(character)
+
+	assert.Equal(t, steinsGate, resolver.FindAnime("kitsu/anime", "5646"))
+	assert.Equal(t, steinsGate, resolver.FindAnime("thetvdb/anime", "244061"))
+	assert.Equal(t, character, resolver.FindCharacter("kitsu/character", "5646"))
+	assert.Nil(t, resolver.FindAnime("kitsu/character", "5646"))
+	assert.Nil(t, resolver.Find("anidb/anime", "7729"))
+	assert.Equal(t, []string{"kitsu/anime", "kitsu/character", "myanimelist/anime", "thetvdb/anime"}, resolver.Services())
+
+	// Re-adding updates the index
+	steinsGate.RemoveMapping("thetvdb/anime")
+	resolver.Add(steinsGate)
+	assert.Nil(t, resolver.Find("thetvdb/anime", "244061"))
+	assert.Len(t, resolver.Objects(), 2)
+
+	resolver.Remove(steinsGate)
+	assert.Nil(t, resolver.Find("kitsu/anime", "5646"))
+	assert.Len(t, resolver.Objects(), 1)
+}
+
+func TestMappingResolverConflicts(t *testing.T) {
+	resolver := arn.NewMappingResolver()
+	resolver.Add(newTestMappedAnime("a", &arn.Mapping{Service: "myanimelist/anime", ServiceID: "1"}))
+	resolver.Add(newTestMappedAnime("b", &arn.Mapping{Service: "myanimelist/anime", ServiceID: "1"}))
+	resolver.Add(newTestMappedAnime("c",
+		&arn.Mapping{Service: "kitsu/anime", ServiceID: "2"},
+		&arn.Mapping{Service: "kitsu/anime", ServiceID: "3"},
+	))
+	resolver.Add(newTestMappedAnime("d",
+		&arn.Mapping{Service: "anidb/anime", ServiceID: "4"},
+		&arn.Mapping{Service: "anidb/anime", ServiceID: "4"},
+	))
+
+	// Duplicate IDs are ambiguous
+	assert.Nil(t, resolver.Find("myanimelist/anime", "1"))
+	assert.Len(t, resolver.FindAll("myanimelist/anime", "1"), 2)
+
+	// Repeated identical mappings are not ambiguous
+	assert.NotNil(t, resolver.Find("anidb/anime", "4"))
+
+	conflicts := resolver.Conflicts()
+	assert.Len(t, conflicts, 2)
+
+	assert.Equal(t, arn.MappingConflictDuplicate, conflicts[0].Kind)
+	assert.Equal(t, "myanimelist/anime", conflicts[0].Service)
+	assert.Equal(t, []string{"a", "b"}, conflicts[0].ObjectIDs)
+
+	assert.Equal(t, arn.MappingConflictMultiple, conflicts[1].Kind)
+	assert.Equal(t, "kitsu/anime", conflicts[1].Service)
+	assert.Equal(t, []string{"2", "3"}, conflicts[1].ServiceIDs)
+	assert.Equal(t, []string{"c"}, conflicts[1].ObjectIDs)
+}
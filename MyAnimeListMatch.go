@@ -17,3 +17,29 @@ func (match *MyAnimeListMatch) JSON() string {
 	PanicOnError(err)
 	return string(b)
 }
+
+// ImportFields returns the anime list item fields that MyAnimeList entries supply.
+// Notes and rewatch count are not part of the entries.
+func (match *MyAnimeListMatch) ImportFields() []string {
+	return []string{
+		ImportFieldStatus,
+		ImportFieldEpisodes,
+		ImportFieldRating,
+	}
+}
+
+// AnimeListItem converts the MyAnimeList entry to an anime list item.
+// MyAnimeList scores already use our 0-10 scale.
+// The entries have no dates, therefore the item is created at the time of the import
+// and has no edit date.
+func (match *MyAnimeListMatch) AnimeListItem() *AnimeListItem {
+	return &AnimeListItem{
+		AnimeID:  match.ARNAnime.ID,
+		Status:   MyAnimeListStatusToARNStatus(match.MyAnimeListItem.Status),
+		Episodes: match.MyAnimeListItem.NumWatchedEpisodes,
+		Rating: AnimeListItemRating{
+			Overall: float64(match.MyAnimeListItem.Score),
+		},
+		Created: DateTimeUTC(),
+	}
+}
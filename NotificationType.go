@@ -2,13 +2,14 @@ package arn
 
 // NotificationType values
 const (
-	NotificationTypeTest          = "test"
-	NotificationTypeAnimeEpisode  = "anime-episode"
-	NotificationTypeAnimeFinished = "anime-finished"
-	NotificationTypeForumReply    = "forum-reply"
-	NotificationTypeFollow        = "follow"
-	NotificationTypeLike          = "like"
-	NotificationTypePurchase      = "purchase"
-	NotificationTypePackageTest   = "package-test"
-	NotificationTypeGroupJoin     = "group-join"
+	NotificationTypeTest              = "test"
+	NotificationTypeAnimeEpisode      = "anime-episode"
+	NotificationTypeAnimeFinished     = "anime-finished"
+	NotificationTypeAnimeEpisodeDelay = "anime-episode-delay"
+	NotificationTypeForumReply        = "forum-reply"
+	NotificationTypeFollow            = "follow"
+	NotificationTypeLike              = "like"
+	NotificationTypePurchase          = "purchase"
+	NotificationTypePackageTest       = "package-test"
+	NotificationTypeGroupJoin         = "group-join"
 )
@@ -277,10 +277,13 @@ func (post *Post) Delete() error {
 	}
 
 	DB.Delete("Post", post.ID)
+	notifyDelete("Post", post.ID)
+
 	return nil
 }
 
 // Save saves the post object in the database.
 func (post *Post) Save() {
 	DB.Set("Post", post.ID, post)
+	notifySave("Post", post)
 }
@@ -0,0 +1,292 @@
+package arn
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Names of the built-in ranking strategies
+const (
+	RankingQuality       = "quality"
+	RankingTrending      = "trending"
+	RankingHiddenGems    = "hidden-gems"
+	RankingControversial = "controversial"
+)
+
+// RankingStrategy calculates a score for anime rankings, higher scores are ranked first.
+type RankingStrategy interface {
+	Name() string
+	Score(anime *Anime, context *RankingContext) float64
+}
+
+// RankingContext contains the information about the ranking that is not part of the anime.
+type RankingContext struct {
+	// FilterStatus is the status the ranked anime have been filtered by, if any.
+	FilterStatus string
+
+	// Now is the reference time for age calculations.
+	Now time.Time
+}
+
+// rankingStrategies holds all registered strategies.
+var rankingStrategies = struct {
+	sync.RWMutex
+	value map[string]RankingStrategy
+}{
+	value: map[string]RankingStrategy{},
+}
+
+// rankingStrategyConstructors creates the built-in strategies for the given weights.
+var rankingStrategyConstructors = map[string]func(RankingWeights) RankingStrategy{
+	RankingQuality:       func(weights RankingWeights) RankingStrategy { return &QualityRanking{weights} },
+	RankingTrending:      func(weights RankingWeights) RankingStrategy { return &TrendingRanking{weights} },
+	RankingHiddenGems:    func(weights RankingWeights) RankingStrategy { return &HiddenGemsRanking{weights} },
+	RankingControversial: func(weights RankingWeights) RankingStrategy { return &ControversialRanking{weights} },
+}
+
+// Register the built-in strategies with the default weights.
+func init() {
+	for name := range rankingStrategyConstructors {
+		strategy, _ := NewRankingStrategy(name, DefaultRankingWeights)
+		RegisterRankingStrategy(strategy)
+	}
+}
+
+// NewRankingStrategy creates a built-in ranking strategy with the given weights.
+func NewRankingStrategy(name string, weights RankingWeights) (RankingStrategy, error) {
+	constructor, exists := rankingStrategyConstructors[name]
+
+	if !exists {
+		return nil, errors.New("Unknown ranking strategy: " + name)
+	}
+
+	return constructor(weights), nil
+}
+
+// RegisterRankingStrategy adds the strategy or replaces the one with the same name.
+func RegisterRankingStrategy(strategy RankingStrategy) {
+	rankingStrategies.Lock()
+	defer rankingStrategies.Unlock()
+
+	rankingStrategies.value[strategy.Name()] = strategy
+}
+
+// GetRankingStrategy returns the strategy registered under the given name.
+func GetRankingStrategy(name string) (RankingStrategy, error) {
+	rankingStrategies.RLock()
+	defer rankingStrategies.RUnlock()
+
+	strategy, exists := rankingStrategies.value[name]
+
+	if !exists {
+		return nil, errors.New("Unknown ranking strategy: " + name)
+	}
+
+	return strategy, nil
+}
+
+// RankingStrategyNames returns the sorted names of all registered strategies.
+func RankingStrategyNames() []string {
+	rankingStrategies.RLock()
+	defer rankingStrategies.RUnlock()
+
+	names := make([]string, 0, len(rankingStrategies.value))
+
+	for name := range rankingStrategies.value {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// SortAnimeByStrategy sorts the given slice of anime by the scores of the strategy.
+// Anime with equal scores are sorted by their canonical title.
+func SortAnimeByStrategy(animes []*Anime, strategy RankingStrategy, context *RankingContext) {
+	scores := make(map[*Anime]float64, len(animes))
+
+	for _, anime := range animes {
+		scores[anime] = strategy.Score(anime, context)
+	}
+
+	sort.SliceStable(animes, func(i, j int) bool {
+		a := animes[i]
+		b := animes[j]
+
+		if scores[a] == scores[b] {
+			return a.Title.Canonical < b.Title.Canonical
+		}
+
+		return scores[a] > scores[b]
+	})
+}
+
+// RankingCorrelation returns the Kendall rank correlation of two rankings in the range [-1, 1].
+// Only anime that appear in both rankings are taken into account.
+// Identical orders return 1 and reversed orders return -1.
+func RankingCorrelation(a []*Anime, b []*Anime) float64 {
+	positions := map[string]int{}
+
+	for index, anime := range b {
+		positions[anime.ID] = index
+	}
+
+	var common []int
+
+	for _, anime := range a {
+		position, exists := positions[anime.ID]
+
+		if exists {
+			common = append(common, position)
+		}
+	}
+
+	if len(common) < 2 {
+		return 1
+	}
+
+	concordant := 0
+	discordant := 0
+
+	for i := 0; i < len(common); i++ {
+		for j := i + 1; j < len(common); j++ {
+			if common[i] < common[j] {
+				concordant++
+			} else {
+				discordant++
+			}
+		}
+	}
+
+	return float64(concordant-discordant) / float64(concordant+discordant)
+}
+
+// QualityRanking ranks anime by their ratings and popularity.
+type QualityRanking struct {
+	Weights RankingWeights
+}
+
+// Name returns the name of the strategy.
+func (ranking *QualityRanking) Name() string {
+	return RankingQuality
+}
+
+// Score returns the quality score of the anime.
+func (ranking *QualityRanking) Score(anime *Anime, context *RankingContext) float64 {
+	weights := &ranking.Weights
+	rating := anime.Rating.Bayesian()
+	score := rating.Overall
+	score += rating.Story * weights.StoryWeight
+	score += rating.Visuals * weights.VisualsWeight
+	score += rating.Soundtrack * weights.SoundtrackWeight
+	score += popularityScore(anime, weights)
+
+	if anime.Status == "current" {
+		score += weights.CurrentlyAiringBonus
+	}
+
+	if anime.Type == "movie" {
+		score += weights.MovieBonus
+	}
+
+	if anime.Popularity.Total() < weights.PopularityThreshold {
+		score -= weights.PopularityPenalty
+	}
+
+	if len(anime.Summary) >= weights.LongSummaryLength {
+		score += weights.LongSummaryBonus
+	}
+
+	// If we show currently running shows, rank shows that started a long time ago a bit lower
+	if context.FilterStatus == "current" && anime.StartDate != "" && context.Now.Sub(anime.StartDateTime()) > weights.AgeThreshold() {
+		score -= weights.AgePenalty
+	}
+
+	return score
+}
+
+// TrendingRanking prefers anime that many users are currently watching or planning to watch
+// and that are currently airing or started recently.
+type TrendingRanking struct {
+	Weights RankingWeights
+}
+
+// Name returns the name of the strategy.
+func (ranking *TrendingRanking) Name() string {
+	return RankingTrending
+}
+
+// Score returns the trending score of the anime.
+func (ranking *TrendingRanking) Score(anime *Anime, context *RankingContext) float64 {
+	weights := &ranking.Weights
+	score := anime.Rating.Bayesian().Overall
+	score += float64(anime.Popularity.Watching) * weights.WatchingPopularityWeight
+	score += float64(anime.Popularity.Planned) * weights.PlannedPopularityWeight
+
+	if anime.Status == "current" {
+		score += weights.CurrentlyAiringBonus
+	}
+
+	if anime.StartDate != "" {
+		age := context.Now.Sub(anime.StartDateTime()).Hours() / 24 / 365
+
+		if age > 0 {
+			score -= age * weights.AgePenaltyPerYear
+		}
+	}
+
+	return score
+}
+
+// HiddenGemsRanking prefers highly rated anime that only few users know.
+type HiddenGemsRanking struct {
+	Weights RankingWeights
+}
+
+// Name returns the name of the strategy.
+func (ranking *HiddenGemsRanking) Name() string {
+	return RankingHiddenGems
+}
+
+// Score returns the hidden gem score of the anime.
+func (ranking *HiddenGemsRanking) Score(anime *Anime, context *RankingContext) float64 {
+	weights := &ranking.Weights
+	score := anime.Rating.Bayesian().Overall
+	score -= math.Log1p(float64(anime.Popularity.Total())) * weights.PopularityLogWeight
+
+	if anime.Rating.Count.Overall < RatingCountThreshold {
+		score -= weights.PopularityPenalty
+	}
+
+	return score
+}
+
+// ControversialRanking prefers anime whose ratings disagree the most.
+type ControversialRanking struct {
+	Weights RankingWeights
+}
+
+// Name returns the name of the strategy.
+func (ranking *ControversialRanking) Name() string {
+	return RankingControversial
+}
+
+// Score returns the controversy score of the anime.
+// The deviation is dampened for anime with only a few ratings.
+func (ranking *ControversialRanking) Score(anime *Anime, context *RankingContext) float64 {
+	count := float64(anime.Rating.Count.Overall)
+	confidence := count / (count + RatingCountThreshold)
+	return anime.Rating.Deviation.Overall * confidence * ranking.Weights.DeviationWeight
+}
+
+// popularityScore returns the weighted sum of the list statuses of the anime.
+func popularityScore(anime *Anime, weights *RankingWeights) float64 {
+	score := float64(anime.Popularity.Watching) * weights.WatchingPopularityWeight
+	score += float64(anime.Popularity.Planned) * weights.PlannedPopularityWeight
+	score += float64(anime.Popularity.Completed) * weights.CompletedPopularityWeight
+	score += float64(anime.Popularity.Dropped) * weights.DroppedPopularityWeight
+	return score
+}
@@ -0,0 +1,131 @@
+package arn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+// rankingNow is the fixed reference time for deterministic rankings.
+var rankingNow = time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+// newRankingFixture creates an anime with the given rating statistics and popularity.
+func newRankingFixture(id string, overall float64, count int, deviation float64, popularity int, startDate string, status string) *arn.Anime {
+	anime := &arn.Anime{
+		HasID:      arn.HasID{ID: id},
+		Title:      &arn.AnimeTitle{Canonical: id},
+		Type:       "tv",
+		Status:     status,
+		StartDate:  startDate,
+		Rating:     &arn.AnimeRating{},
+		Popularity: &arn.AnimePopularity{Completed: popularity},
+	}
+
+	anime.Rating.Overall = overall
+	anime.Rating.Count.Overall = count
+	anime.Rating.Deviation.Overall = deviation
+	return anime
+}
+
+func rankingFixtures() []*arn.Anime {
+	newHit := newRankingFixture("new-hit", 8.0, 300, 1.5, 0, "2019-04-07", "current")
+	newHit.Popularity.Watching = 800
+
+	return []*arn.Anime{
+		newRankingFixture("classic", 9.0, 2000, 1.0, 3000, "2006-04-05", "finished"),
+		newHit,
+		newRankingFixture("gem", 9.2, 40, 0.8, 50, "2012-01-10", "finished"),
+		newRankingFixture("divisive", 6.0, 500, 3.5, 600, "2015-07-01", "finished"),
+		newRankingFixture("unknown", 10.0, 1, 0.0, 1, "2018-01-01", "finished"),
+	}
+}
+
+func rankWith(t *testing.T, name string, context *arn.RankingContext) []string {
+	strategy, err := arn.GetRankingStrategy(name)
+	assert.NoError(t, err)
+
+	animes := rankingFixtures()
+	arn.SortAnimeByStrategy(animes, strategy, context)
+
+	ids := make([]string, len(animes))
+
+	for i, anime := range animes {
+		ids[i] = anime.ID
+	}
+
+	return ids
+}
+
+func TestRankingStrategies(t *testing.T) {
+	context := &arn.RankingContext{Now: rankingNow}
+
+	assert.Equal(t, []string{
+		arn.RankingControversial,
+		arn.RankingHiddenGems,
+		arn.RankingQuality,
+		arn.RankingTrending,
+	}, arn.RankingStrategyNames())
+
+	assert.Equal(t, []string{"classic", "new-hit", "divisive", "gem", "unknown"}, rankWith(t, arn.RankingQuality, context))
+	assert.Equal(t, "new-hit", rankWith(t, arn.RankingTrending, context)[0])
+	assert.Equal(t, "gem", rankWith(t, arn.RankingHiddenGems, context)[0])
+	assert.Equal(t, "divisive", rankWith(t, arn.RankingControversial, context)[0])
+	assert.Equal(t, "unknown", rankWith(t, arn.RankingControversial, context)[4])
+
+	// Currently airing anime that started long ago are penalized
+	old := newRankingFixture("old", 8.0, 300, 1.5, 800, "2017-04-07", "current")
+	strategy, _ := arn.GetRankingStrategy(arn.RankingQuality)
+	assert.True(t, strategy.Score(old, &arn.RankingContext{FilterStatus: "current", Now: rankingNow}) < strategy.Score(old, context))
+
+	_, err := arn.GetRankingStrategy("random")
+	assert.Error(t, err)
+}
+
+func TestRankingDeterminism(t *testing.T) {
+	context := &arn.RankingContext{Now: rankingNow}
+
+	for _, name := range arn.RankingStrategyNames() {
+		assert.Equal(t, rankWith(t, name, context), rankWith(t, name, context), name)
+	}
+}
+
+func TestLoadRankingWeights(t *testing.T) {
+	defer func() {
+		strategy, _ := arn.NewRankingStrategy(arn.RankingQuality, arn.DefaultRankingWeights)
+		arn.RegisterRankingStrategy(strategy)
+	}()
+
+	context := &arn.RankingContext{Now: rankingNow}
+	before := rankingFixtures()
+	strategy, _ := arn.GetRankingStrategy(arn.RankingQuality)
+	arn.SortAnimeByStrategy(before, strategy, context)
+
+	// Without popularity weights the quality ranking only depends on the ratings
+	err := arn.LoadRankingWeights([]byte(`{"quality": {"completedPopularityWeight": 0, "watchingPopularityWeight": 0, "currentlyAiringBonus": 0}}`))
+	assert.NoError(t, err)
+
+	after := rankingFixtures()
+	strategy, _ = arn.GetRankingStrategy(arn.RankingQuality)
+	arn.SortAnimeByStrategy(after, strategy, context)
+
+	assert.Equal(t, []string{"classic", "gem", "new-hit", "divisive", "unknown"}, []string{after[0].ID, after[1].ID, after[2].ID, after[3].ID, after[4].ID})
+	assert.True(t, arn.RankingCorrelation(before, after) < 1)
+	assert.Equal(t, 1.0, arn.RankingCorrelation(after, after))
+
+	assert.Error(t, arn.LoadRankingWeights([]byte(`{"unknown-strategy": {}}`)))
+	assert.Error(t, arn.LoadRankingWeights([]byte(`{"quality": `)))
+}
+
+func TestRankingCorrelation(t *testing.T) {
+	animes := rankingFixtures()
+	reversed := make([]*arn.Anime, len(animes))
+
+	for i, anime := range animes {
+		reversed[len(animes)-1-i] = anime
+	}
+
+	assert.Equal(t, 1.0, arn.RankingCorrelation(animes, animes))
+	assert.Equal(t, -1.0, arn.RankingCorrelation(animes, reversed))
+}
@@ -0,0 +1,106 @@
+package arn
+
+import (
+	"io/ioutil"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// RankingWeights contains the configurable parameters of the ranking strategies.
+// Each strategy only uses the weights relevant to it.
+type RankingWeights struct {
+	CurrentlyAiringBonus      float64 `json:"currentlyAiringBonus"`
+	LongSummaryBonus          float64 `json:"longSummaryBonus"`
+	LongSummaryLength         int     `json:"longSummaryLength"`
+	PopularityThreshold       int     `json:"popularityThreshold"`
+	PopularityPenalty         float64 `json:"popularityPenalty"`
+	WatchingPopularityWeight  float64 `json:"watchingPopularityWeight"`
+	CompletedPopularityWeight float64 `json:"completedPopularityWeight"`
+	PlannedPopularityWeight   float64 `json:"plannedPopularityWeight"`
+	DroppedPopularityWeight   float64 `json:"droppedPopularityWeight"`
+	StoryWeight               float64 `json:"storyWeight"`
+	VisualsWeight             float64 `json:"visualsWeight"`
+	SoundtrackWeight          float64 `json:"soundtrackWeight"`
+	MovieBonus                float64 `json:"movieBonus"`
+	AgePenalty                float64 `json:"agePenalty"`
+	AgeThresholdDays          int     `json:"ageThresholdDays"`
+	AgePenaltyPerYear         float64 `json:"agePenaltyPerYear"`
+	PopularityLogWeight       float64 `json:"popularityLogWeight"`
+	DeviationWeight           float64 `json:"deviationWeight"`
+}
+
+// DefaultRankingWeights are the weights used when no configuration has been loaded.
+var DefaultRankingWeights = RankingWeights{
+	CurrentlyAiringBonus:      5.0,
+	LongSummaryBonus:          0.1,
+	LongSummaryLength:         140,
+	PopularityThreshold:       5,
+	PopularityPenalty:         8.0,
+	WatchingPopularityWeight:  0.07,
+	CompletedPopularityWeight: 0.07,
+	PlannedPopularityWeight:   0.07 * (2.0 / 3.0),
+	DroppedPopularityWeight:   -0.07 * (2.0 / 3.0),
+	StoryWeight:               0.0075,
+	VisualsWeight:             0.0075,
+	SoundtrackWeight:          0.0075,
+	MovieBonus:                0.28,
+	AgePenalty:                11.0,
+	AgeThresholdDays:          6 * 30,
+	AgePenaltyPerYear:         2.0,
+	PopularityLogWeight:       1.0,
+	DeviationWeight:           2.0,
+}
+
+// AgeThreshold returns the age after which currently airing anime are penalized.
+func (weights *RankingWeights) AgeThreshold() time.Duration {
+	return time.Duration(weights.AgeThresholdDays) * 24 * time.Hour
+}
+
+// LoadRankingWeights configures the ranking strategies from JSON data.
+// The data maps strategy names to weights, e.g. {"quality": {"movieBonus": 0.5}}.
+// Weights that are not specified keep their default value.
+func LoadRankingWeights(data []byte) error {
+	config := map[string]jsoniter.RawMessage{}
+	err := jsoniter.Unmarshal(data, &config)
+
+	if err != nil {
+		return err
+	}
+
+	strategies := make([]RankingStrategy, 0, len(config))
+
+	for name, rawWeights := range config {
+		weights := DefaultRankingWeights
+		err := jsoniter.Unmarshal(rawWeights, &weights)
+
+		if err != nil {
+			return err
+		}
+
+		strategy, err := NewRankingStrategy(name, weights)
+
+		if err != nil {
+			return err
+		}
+
+		strategies = append(strategies, strategy)
+	}
+
+	for _, strategy := range strategies {
+		RegisterRankingStrategy(strategy)
+	}
+
+	return nil
+}
+
+// LoadRankingWeightsFile configures the ranking strategies from a JSON file.
+func LoadRankingWeightsFile(file string) error {
+	data, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return err
+	}
+
+	return LoadRankingWeights(data)
+}
@@ -0,0 +1,386 @@
+package arn
+
+import (
+	"math"
+	"sort"
+)
+
+// Weights used for anime recommendations.
+const (
+	// recommendationNeighbors is the number of similar anime kept per anime.
+	recommendationNeighbors = 50
+
+	// recommendationMinCoOccurrence is the number of users who need to have watched
+	// both anime before their collaborative similarity is taken into account.
+	recommendationMinCoOccurrence = 2
+
+	// recommendationMaxListItems limits the number of anime per list that are used
+	// for the collaborative similarity. Longer lists only contribute their best rated anime.
+	recommendationMaxListItems = 500
+
+	// unratedAffinity is used for completed or watching anime without a rating.
+	unratedAffinity = 0.3
+
+	// highRatingThreshold is the overall rating that counts as "rated highly".
+	highRatingThreshold = 8.0
+
+	// Weights for the content based similarity signals.
+	genreSimilarityWeight    = 0.3
+	studioSimilarityWeight   = 0.2
+	relationSimilarityWeight = 0.5
+)
+
+// Recommendation reasons
+const (
+	RecommendationReasonRated   = "rated"
+	RecommendationReasonRelated = "related"
+	RecommendationReasonWatched = "watched"
+)
+
+// RecommendationModel contains the anime similarities needed to recommend anime.
+// Building the model is expensive and should be done in a background job.
+type RecommendationModel struct {
+	neighbors map[string]map[string]float64
+	genres    map[string][]string
+	studios   map[string][]string
+	relations map[string]map[string]bool
+}
+
+// NewRecommendationModel builds an item-based recommendation model.
+// Collaborative similarity is the cosine similarity of rating-weighted co-occurrences
+// in completed and watching lists. Private list items are ignored.
+func NewRecommendationModel(lists []*AnimeList, animes []*Anime, relations []*AnimeRelations) *RecommendationModel {
+	model := &RecommendationModel{
+		neighbors: map[string]map[string]float64{},
+		genres:    map[string][]string{},
+		studios:   map[string][]string{},
+		relations: map[string]map[string]bool{},
+	}
+
+	for _, anime := range animes {
+		model.genres[anime.ID] = anime.Genres
+		model.studios[anime.ID] = anime.StudioIDs
+	}
+
+	for _, animeRelations := range relations {
+		for _, relation := range animeRelations.Items {
+			model.addRelation(animeRelations.AnimeID, relation.AnimeID)
+			model.addRelation(relation.AnimeID, animeRelations.AnimeID)
+		}
+	}
+
+	// Sparse rating vectors of the lists and the inverted index from anime to lists
+	vectors := make([][]*recommendationWeight, 0, len(lists))
+	watchers := map[string][]*recommendationWeight{}
+	norms := map[string]float64{}
+
+	for _, list := range lists {
+		vector := listWeights(list)
+
+		for _, weight := range vector {
+			norms[weight.AnimeID] += weight.Value * weight.Value
+		}
+
+		// Lists with a single anime don't contain any co-occurrences
+		if len(vector) < 2 {
+			continue
+		}
+
+		listIndex := len(vectors)
+		vectors = append(vectors, vector)
+
+		for _, weight := range vector {
+			weight.list = listIndex
+			watchers[weight.AnimeID] = append(watchers[weight.AnimeID], weight)
+		}
+	}
+
+	// The similarities are calculated one anime at a time so that only the
+	// neighbors of a single anime are kept in memory besides the vectors.
+	for a, animeWatchers := range watchers {
+		products := map[string]float64{}
+		coOccurrences := map[string]int{}
+
+		for _, watcher := range animeWatchers {
+			for _, other := range vectors[watcher.list] {
+				if other.AnimeID == a {
+					continue
+				}
+
+				products[other.AnimeID] += watcher.Value * other.Value
+				coOccurrences[other.AnimeID]++
+			}
+		}
+
+		similarities := map[string]float64{}
+
+		for b, product := range products {
+			if coOccurrences[b] < recommendationMinCoOccurrence {
+				continue
+			}
+
+			similarities[b] = product / math.Sqrt(norms[a]*norms[b])
+		}
+
+		if len(similarities) > 0 {
+			model.neighbors[a] = topSimilarities(similarities, recommendationNeighbors)
+		}
+	}
+
+	return model
+}
+
+// Similarity returns the combined collaborative and content based similarity of two anime.
+func (model *RecommendationModel) Similarity(animeID string, otherID string) float64 {
+	return model.neighbors[animeID][otherID] + model.contentSimilarity(animeID, otherID)
+}
+
+// Recommend returns the n best anime the owner of the list hasn't added yet.
+func (model *RecommendationModel) Recommend(list *AnimeList, n int) []*AnimeRecommendation {
+	list.Lock()
+	seeds := map[string]*AnimeListItem{}
+	known := map[string]bool{}
+
+	for _, item := range list.Items {
+		known[item.AnimeID] = true
+
+		if seedAffinity(item) > 0 {
+			seeds[item.AnimeID] = item
+		}
+	}
+
+	list.Unlock()
+
+	recommendations := map[string]*AnimeRecommendation{}
+	bestContributions := map[string]float64{}
+
+	for seedID, item := range seeds {
+		affinity := seedAffinity(item)
+
+		for candidateID := range model.candidates(seedID) {
+			if known[candidateID] {
+				continue
+			}
+
+			contribution := affinity * model.Similarity(seedID, candidateID)
+
+			if contribution <= 0 {
+				continue
+			}
+
+			recommendation, exists := recommendations[candidateID]
+
+			if !exists {
+				recommendation = &AnimeRecommendation{
+					AnimeID: candidateID,
+				}
+
+				recommendations[candidateID] = recommendation
+			}
+
+			recommendation.Score += contribution
+
+			// The seed with the biggest contribution explains the recommendation
+			if contribution > bestContributions[candidateID] || (contribution == bestContributions[candidateID] && seedID < recommendation.BecauseOf) {
+				bestContributions[candidateID] = contribution
+				recommendation.BecauseOf = seedID
+				recommendation.Reason = model.reason(item, candidateID)
+			}
+		}
+	}
+
+	results := make([]*AnimeRecommendation, 0, len(recommendations))
+
+	for _, recommendation := range recommendations {
+		results = append(results, recommendation)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].AnimeID < results[j].AnimeID
+		}
+
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > n {
+		results = results[:n]
+	}
+
+	return results
+}
+
+// candidates returns the anime that could be recommended because of the seed anime.
+func (model *RecommendationModel) candidates(seedID string) map[string]bool {
+	candidates := map[string]bool{}
+
+	for animeID := range model.neighbors[seedID] {
+		candidates[animeID] = true
+	}
+
+	for animeID := range model.relations[seedID] {
+		candidates[animeID] = true
+	}
+
+	return candidates
+}
+
+// contentSimilarity compares genres, studios and relations of two anime.
+func (model *RecommendationModel) contentSimilarity(animeID string, otherID string) float64 {
+	similarity := genreSimilarityWeight * jaccardSimilarity(model.genres[animeID], model.genres[otherID])
+
+	if jaccardSimilarity(model.studios[animeID], model.studios[otherID]) > 0 {
+		similarity += studioSimilarityWeight
+	}
+
+	if model.relations[animeID][otherID] {
+		similarity += relationSimilarityWeight
+	}
+
+	return similarity
+}
+
+// reason returns the reason why the seed led to the recommendation of the candidate.
+func (model *RecommendationModel) reason(seed *AnimeListItem, candidateID string) string {
+	switch {
+	case seed.Rating.Overall >= highRatingThreshold:
+		return RecommendationReasonRated
+	case model.relations[seed.AnimeID][candidateID]:
+		return RecommendationReasonRelated
+	default:
+		return RecommendationReasonWatched
+	}
+}
+
+// addRelation adds a one-directional relation between two anime.
+func (model *RecommendationModel) addRelation(animeID string, relatedID string) {
+	if model.relations[animeID] == nil {
+		model.relations[animeID] = map[string]bool{}
+	}
+
+	model.relations[animeID][relatedID] = true
+}
+
+// recommendationWeight is the weight of an anime in a list.
+type recommendationWeight struct {
+	AnimeID string
+	Value   float64
+
+	// list is the index of the list vector the weight belongs to.
+	list int
+}
+
+// listWeights returns the weights of all public completed and watching anime in the list.
+// Rated anime are weighted by their overall rating. Only the recommendationMaxListItems
+// anime with the highest weights are returned.
+func listWeights(list *AnimeList) []*recommendationWeight {
+	list.Lock()
+	defer list.Unlock()
+
+	var weights []*recommendationWeight
+	seen := map[string]bool{}
+
+	for _, item := range list.Items {
+		if item.Private || (item.Status != AnimeListStatusCompleted && item.Status != AnimeListStatusWatching) {
+			continue
+		}
+
+		if seen[item.AnimeID] {
+			continue
+		}
+
+		seen[item.AnimeID] = true
+		value := AverageRating / MaxRating
+
+		if item.Rating.Overall != 0 {
+			value = item.Rating.Overall / MaxRating
+		}
+
+		weights = append(weights, &recommendationWeight{
+			AnimeID: item.AnimeID,
+			Value:   value,
+		})
+	}
+
+	if len(weights) > recommendationMaxListItems {
+		sort.Slice(weights, func(i, j int) bool {
+			if weights[i].Value == weights[j].Value {
+				return weights[i].AnimeID < weights[j].AnimeID
+			}
+
+			return weights[i].Value > weights[j].Value
+		})
+
+		weights = weights[:recommendationMaxListItems]
+	}
+
+	return weights
+}
+
+// seedAffinity tells you how much the user liked the anime.
+// Only anime with a positive affinity are used to find recommendations.
+func seedAffinity(item *AnimeListItem) float64 {
+	if item.Status != AnimeListStatusCompleted && item.Status != AnimeListStatusWatching {
+		return 0
+	}
+
+	if item.Rating.Overall == 0 {
+		return unratedAffinity
+	}
+
+	return (item.Rating.Overall - AverageRating) / (MaxRating - AverageRating)
+}
+
+// topSimilarities returns the n entries with the highest similarity.
+func topSimilarities(similarities map[string]float64, n int) map[string]float64 {
+	if len(similarities) <= n {
+		return similarities
+	}
+
+	ids := make([]string, 0, len(similarities))
+
+	for id := range similarities {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if similarities[ids[i]] == similarities[ids[j]] {
+			return ids[i] < ids[j]
+		}
+
+		return similarities[ids[i]] > similarities[ids[j]]
+	})
+
+	top := make(map[string]float64, n)
+
+	for _, id := range ids[:n] {
+		top[id] = similarities[id]
+	}
+
+	return top
+}
+
+// jaccardSimilarity returns the size of the intersection divided by the size of the union.
+func jaccardSimilarity(a []string, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	union := map[string]bool{}
+	intersection := 0
+
+	for _, value := range a {
+		union[value] = true
+	}
+
+	for _, value := range b {
+		if union[value] {
+			intersection++
+			continue
+		}
+
+		union[value] = true
+	}
+
+	return float64(intersection) / float64(len(union))
+}
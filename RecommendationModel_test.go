@@ -0,0 +1,81 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestList(userID string, ratings map[string]float64) *arn.AnimeList {
+	list := &arn.AnimeList{UserID: userID}
+
+	for animeID, rating := range ratings {
+		item := &arn.AnimeListItem{
+			AnimeID: animeID,
+			Status:  arn.AnimeListStatusCompleted,
+		}
+
+		item.Rating.Overall = rating
+		list.Items = append(list.Items, item)
+	}
+
+	return list
+}
+
+func TestRecommendationModel(t *testing.T) {
+	lists := []*arn.AnimeList{
+		newTestList("a", map[string]float64{"steins-gate": 10, "erased": 9, "clannad": 4}),
+		newTestList("b", map[string]float64{"steins-gate": 9, "erased": 8, "clannad": 5}),
+		newTestList("c", map[string]float64{"steins-gate": 8, "erased": 9, "k-on": 3}),
+		newTestList("d", map[string]float64{"k-on": 9, "clannad": 9}),
+		newTestList("e", map[string]float64{"k-on": 8, "clannad": 8}),
+	}
+
+	animes := []*arn.Anime{
+		{HasID: arn.HasID{ID: "steins-gate"}, Genres: []string{"Sci-Fi", "Thriller"}},
+		{HasID: arn.HasID{ID: "erased"}, Genres: []string{"Mystery", "Thriller"}},
+		{HasID: arn.HasID{ID: "clannad"}, Genres: []string{"Drama"}},
+		{HasID: arn.HasID{ID: "k-on"}, Genres: []string{"Music"}},
+		{HasID: arn.HasID{ID: "steins-gate-0"}, Genres: []string{"Sci-Fi", "Thriller"}},
+	}
+
+	relations := []*arn.AnimeRelations{
+		{
+			AnimeID: "steins-gate",
+			Items: []*arn.AnimeRelation{
+				{AnimeID: "steins-gate-0", Type: "sequel"},
+			},
+		},
+	}
+
+	model := arn.NewRecommendationModel(lists, animes, relations)
+
+	assert.True(t, model.Similarity("steins-gate", "erased") > model.Similarity("steins-gate", "clannad"))
+	assert.Equal(t, model.Similarity("erased", "steins-gate"), model.Similarity("steins-gate", "erased"))
+
+	// User "new" loved Steins;Gate and hasn't seen anything else
+	recommendations := model.Recommend(newTestList("new", map[string]float64{"steins-gate": 10}), 2)
+
+	assert.Len(t, recommendations, 2)
+	assert.Equal(t, "erased", recommendations[0].AnimeID)
+	assert.Equal(t, "steins-gate", recommendations[0].BecauseOf)
+	assert.Equal(t, arn.RecommendationReasonRated, recommendations[0].Reason)
+	assert.Equal(t, "steins-gate-0", recommendations[1].AnimeID)
+
+	// Badly rated anime don't lead to recommendations
+	assert.Empty(t, model.Recommend(newTestList("new", map[string]float64{"steins-gate": 2}), 10))
+
+	// Related anime are explained by the relation if the seed wasn't rated highly
+	recommendations = model.Recommend(newTestList("new", map[string]float64{"steins-gate": 7, "erased": 6}), 10)
+	var related *arn.AnimeRecommendation
+
+	for _, recommendation := range recommendations {
+		if recommendation.AnimeID == "steins-gate-0" {
+			related = recommendation
+		}
+	}
+
+	assert.NotNil(t, related)
+	assert.Equal(t, arn.RecommendationReasonRelated, related.Reason)
+}
@@ -1,6 +1,10 @@
 package arn
 
-import "fmt"
+import (
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+)
 
 const (
 	// SortByAiringDate sorts your watching list by airing date.
@@ -59,6 +63,7 @@ type NotificationSettings struct {
 	GroupPostLikes       bool   `json:"groupPostLikes" editable:"true"`
 	QuoteLikes           bool   `json:"quoteLikes" editable:"true"`
 	SoundTrackLikes      bool   `json:"soundTrackLikes" editable:"true"`
+	AnimeEpisodeDelays   bool   `json:"animeEpisodeDelays" editable:"true"`
 }
 
 // EditorSettings ...
@@ -119,6 +124,10 @@ type AvatarSettings struct {
 // CalendarSettings ...
 type CalendarSettings struct {
 	ShowAddedAnimeOnly bool `json:"showAddedAnimeOnly" editable:"true"`
+
+	// FeedAllAnime includes all airing anime in the calendar feed
+	// instead of only the anime on the watching and planned lists.
+	FeedAllAnime bool `json:"feedAllAnime" editable:"true"`
 }
 
 // NewSettings ...
@@ -142,6 +151,7 @@ func NewSettings(user *User) *Settings {
 		},
 		Calendar: CalendarSettings{
 			ShowAddedAnimeOnly: false,
+			FeedAllAnime:       false,
 		},
 		Notification: DefaultNotificationSettings(),
 		Theme:        "light",
@@ -159,9 +169,26 @@ func DefaultNotificationSettings() NotificationSettings {
 		GroupPostLikes:       true,
 		QuoteLikes:           true,
 		SoundTrackLikes:      true,
+		AnimeEpisodeDelays:   true,
 	}
 }
 
+// UnmarshalJSON decodes the notification settings.
+// Settings that are missing in the data because they were saved
+// before the setting existed receive the default value.
+func (settings *NotificationSettings) UnmarshalJSON(data []byte) error {
+	type rawNotificationSettings NotificationSettings
+	raw := rawNotificationSettings(DefaultNotificationSettings())
+	err := jsoniter.Unmarshal(data, &raw)
+
+	if err != nil {
+		return err
+	}
+
+	*settings = NotificationSettings(raw)
+	return nil
+}
+
 // GetSettings ...
 func GetSettings(userID string) (*Settings, error) {
 	obj, err := DB.Get("Settings", userID)
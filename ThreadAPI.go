@@ -148,6 +148,7 @@ func (thread *Thread) AfterEdit(ctx *aero.Context) error {
 // Save saves the thread object in the database.
 func (thread *Thread) Save() {
 	DB.Set("Thread", thread.ID, thread)
+	notifySave("Thread", thread)
 }
 
 // DeleteInContext deletes the thread in the given context.
@@ -183,5 +184,7 @@ func (thread *Thread) Delete() error {
 	}
 
 	DB.Delete("Thread", thread.ID)
+	notifyDelete("Thread", thread.ID)
+
 	return nil
 }
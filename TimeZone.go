@@ -0,0 +1,137 @@
+package arn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTimeZone returns the location for an IANA time zone name like "Europe/Berlin"
+// or a fixed offset like "+09:00", "-0530" or "UTC+9".
+// The second return value is false if the time zone is not valid.
+func ParseTimeZone(zone string) (*time.Location, bool) {
+	zone = strings.TrimSpace(zone)
+
+	if zone == "" {
+		return nil, false
+	}
+
+	if strings.ContainsAny(zone, "+-") {
+		offset, ok := parseTimeZoneOffset(zone)
+
+		if ok {
+			return FixedTimeZone(offset), true
+		}
+
+		// IANA names like "America/Port-au-Prince" can contain a minus sign
+	}
+
+	location, err := time.LoadLocation(zone)
+
+	if err != nil {
+		return nil, false
+	}
+
+	return location, true
+}
+
+// FixedTimeZone returns a location with a fixed offset in seconds east of UTC.
+func FixedTimeZone(offset int) *time.Location {
+	if offset == 0 {
+		return time.UTC
+	}
+
+	sign := '+'
+	absolute := offset
+
+	if offset < 0 {
+		sign = '-'
+		absolute = -offset
+	}
+
+	name := fmt.Sprintf("UTC%c%02d:%02d", sign, absolute/3600, absolute%3600/60)
+	return time.FixedZone(name, offset)
+}
+
+// UserTimeZone returns the time zone of a user.
+// An IANA time zone in the location is preferred because it knows about daylight saving time.
+// Otherwise the offset reported by the browser is used, then the offset in the location.
+// Both the location and the analytics can be nil. Falls back to UTC.
+func UserTimeZone(location *Location, analytics *Analytics) *time.Location {
+	var zone string
+
+	if location != nil {
+		zone = strings.TrimSpace(location.TimeZone)
+	}
+
+	// IANA time zone
+	_, isOffset := parseTimeZoneOffset(zone)
+
+	if !isOffset {
+		timeZone, ok := ParseTimeZone(zone)
+
+		if ok {
+			return timeZone
+		}
+	}
+
+	// Browsers report the offset in minutes west of UTC
+	if analytics != nil {
+		return FixedTimeZone(-analytics.General.TimezoneOffset * 60)
+	}
+
+	// Fixed offset
+	timeZone, ok := ParseTimeZone(zone)
+
+	if ok {
+		return timeZone
+	}
+
+	return time.UTC
+}
+
+// TimeZone returns the time zone of the user.
+func (user *User) TimeZone() *time.Location {
+	return UserTimeZone(user.Location, user.Analytics())
+}
+
+// parseTimeZoneOffset parses offsets like "+09:00", "-0530", "+9" or "UTC+9"
+// and returns the offset in seconds east of UTC.
+func parseTimeZoneOffset(zone string) (int, bool) {
+	zone = strings.TrimPrefix(zone, "UTC")
+	zone = strings.TrimPrefix(zone, "GMT")
+
+	if len(zone) < 2 || (zone[0] != '+' && zone[0] != '-') {
+		return 0, false
+	}
+
+	sign := 1
+
+	if zone[0] == '-' {
+		sign = -1
+	}
+
+	digits := strings.Replace(zone[1:], ":", "", 1)
+	hoursDigits := digits
+	minutesDigits := "0"
+
+	if len(digits) > 2 {
+		hoursDigits = digits[:len(digits)-2]
+		minutesDigits = digits[len(digits)-2:]
+	}
+
+	hours, err := strconv.Atoi(hoursDigits)
+
+	if err != nil || hours < 0 || hours > 14 {
+		return 0, false
+	}
+
+	minutes, err := strconv.Atoi(minutesDigits)
+
+	if err != nil || minutes < 0 || minutes >= 60 {
+		return 0, false
+	}
+
+	return sign * (hours*3600 + minutes*60), true
+}
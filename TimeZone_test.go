@@ -0,0 +1,75 @@
+package arn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeZone(t *testing.T) {
+	offsets := map[string]int{
+		"+09:00":  9 * 3600,
+		"-05:30":  -(5*3600 + 30*60),
+		"+0100":   3600,
+		"UTC+9":   9 * 3600,
+		"GMT-3":   -3 * 3600,
+		"+00:00":  0,
+		" +02:00": 2 * 3600,
+	}
+
+	for zone, offset := range offsets {
+		location, ok := arn.ParseTimeZone(zone)
+		assert.True(t, ok, zone)
+
+		_, actual := time.Date(2019, 1, 1, 0, 0, 0, 0, location).Zone()
+		assert.Equal(t, offset, actual, zone)
+	}
+
+	location, ok := arn.ParseTimeZone("America/Port-au-Prince")
+	assert.True(t, ok)
+	assert.Equal(t, "America/Port-au-Prince", location.String())
+
+	for _, zone := range []string{"", "+25:00", "+05:75", "Mars/Olympus_Mons", "+-5"} {
+		_, ok := arn.ParseTimeZone(zone)
+		assert.False(t, ok, zone)
+	}
+}
+
+func TestUserTimeZone(t *testing.T) {
+	analytics := &arn.Analytics{General: arn.GeneralAnalytics{TimezoneOffset: -120}}
+
+	// IANA time zones are preferred
+	timeZone := arn.UserTimeZone(&arn.Location{TimeZone: "Asia/Tokyo"}, analytics)
+	assert.Equal(t, "Asia/Tokyo", timeZone.String())
+
+	// The browser offset is preferred over the offset of the location
+	timeZone = arn.UserTimeZone(&arn.Location{TimeZone: "+01:00"}, analytics)
+	assert.Equal(t, "UTC+02:00", timeZone.String())
+
+	timeZone = arn.UserTimeZone(&arn.Location{TimeZone: "+01:00"}, nil)
+	assert.Equal(t, "UTC+01:00", timeZone.String())
+
+	assert.Equal(t, time.UTC, arn.UserTimeZone(nil, nil))
+	assert.Equal(t, time.UTC, arn.UserTimeZone(&arn.Location{}, nil))
+}
+
+func TestAiringDateHuman(t *testing.T) {
+	airing := &arn.AiringDate{
+		Start: "2017-05-25T23:30:00+09:00",
+		End:   "2017-05-26T00:00:00+09:00",
+	}
+
+	assert.Equal(t, "Thu, 25 May 2017", airing.StartDateHuman())
+	assert.Equal(t, "14:30:00 UTC", airing.StartTimeHuman())
+
+	tokyo, ok := arn.ParseTimeZone("Asia/Tokyo")
+	assert.True(t, ok)
+	assert.Equal(t, "Thu, 25 May 2017", airing.StartDateHumanIn(tokyo))
+	assert.Equal(t, "Fri, 26 May 2017", airing.EndDateHumanIn(tokyo))
+	assert.Equal(t, "00:00:00 JST", airing.EndTimeHumanIn(tokyo))
+
+	berlin := arn.FixedTimeZone(2 * 3600)
+	assert.Equal(t, "16:30:00 UTC+02:00", airing.StartTimeHumanIn(berlin))
+}
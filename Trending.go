@@ -0,0 +1,277 @@
+package arn
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aerogo/nano"
+)
+
+// Parameters of the trending calculation.
+const (
+	// TrendingSnapshotWindow is the window used for the daily trending snapshots.
+	TrendingSnapshotWindow = 7 * 24 * time.Hour
+
+	// TrendingSnapshotSize is the number of anime stored in a daily snapshot.
+	TrendingSnapshotSize = 100
+
+	// trendingHalfLifeFraction is the half-life of an event relative to the window.
+	trendingHalfLifeFraction = 0.25
+
+	// trendingAdditionWeight is the number of episodes a new list addition is worth.
+	trendingAdditionWeight = 2.0
+
+	// trendingDateFormat is the format of the snapshot IDs.
+	trendingDateFormat = "2006-01-02"
+)
+
+// Trend directions
+const (
+	TrendNew  = "new"
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendSame = "same"
+)
+
+// TrendingEntry is the trending information of a single anime.
+type TrendingEntry struct {
+	AnimeID      string  `json:"animeId"`
+	Score        float64 `json:"score"`
+	Episodes     int     `json:"episodes"`
+	Additions    int     `json:"additions"`
+	Rank         int     `json:"rank"`
+	PreviousRank int     `json:"previousRank"`
+}
+
+// Anime returns the trending anime.
+func (entry *TrendingEntry) Anime() *Anime {
+	anime, _ := GetAnime(entry.AnimeID)
+	return anime
+}
+
+// Movement returns the number of ranks the anime went up since the previous snapshot.
+// Negative values mean that the anime went down.
+func (entry *TrendingEntry) Movement() int {
+	if entry.PreviousRank == 0 {
+		return 0
+	}
+
+	return entry.PreviousRank - entry.Rank
+}
+
+// Trend returns the direction of the rank movement, e.g. for arrows.
+func (entry *TrendingEntry) Trend() string {
+	switch {
+	case entry.PreviousRank == 0:
+		return TrendNew
+	case entry.Movement() > 0:
+		return TrendUp
+	case entry.Movement() < 0:
+		return TrendDown
+	default:
+		return TrendSame
+	}
+}
+
+// TrendingSnapshot is the trending ranking of a single day.
+type TrendingSnapshot struct {
+	Date  string           `json:"date"`
+	Items []*TrendingEntry `json:"items"`
+}
+
+// Rank returns the rank of the anime in the snapshot or 0 if it's not included.
+func (snapshot *TrendingSnapshot) Rank(animeID string) int {
+	for _, entry := range snapshot.Items {
+		if entry.AnimeID == animeID {
+			return entry.Rank
+		}
+	}
+
+	return 0
+}
+
+// GetID returns the date of the snapshot.
+func (snapshot *TrendingSnapshot) GetID() string {
+	return snapshot.Date
+}
+
+// TypeName returns the type name.
+func (snapshot *TrendingSnapshot) TypeName() string {
+	return "TrendingSnapshot"
+}
+
+// Save saves the snapshot in the database.
+func (snapshot *TrendingSnapshot) Save() {
+	DB.Set("TrendingSnapshot", snapshot.Date, snapshot)
+}
+
+// GetTrendingSnapshot returns the snapshot for the given date in the format "2006-01-02".
+func GetTrendingSnapshot(date string) (*TrendingSnapshot, error) {
+	obj, err := DB.Get("TrendingSnapshot", date)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*TrendingSnapshot), nil
+}
+
+// StreamTrendingSnapshots returns a stream of all trending snapshots.
+func StreamTrendingSnapshots() chan *TrendingSnapshot {
+	channel := make(chan *TrendingSnapshot, nano.ChannelBufferSize)
+
+	go func() {
+		for obj := range DB.All("TrendingSnapshot") {
+			channel <- obj.(*TrendingSnapshot)
+		}
+
+		close(channel)
+	}()
+
+	return channel
+}
+
+// TrendingAnime returns the n anime with the highest watch velocity within the window.
+// The previous ranks are taken from yesterday's snapshot, which only exists
+// for the snapshot window. Other windows don't have previous ranks.
+func TrendingAnime(window time.Duration, n int) []*TrendingEntry {
+	now := time.Now().UTC()
+	lists, _ := AllAnimeLists()
+	entries := CalculateTrending(StreamActivityConsumeAnimes(), lists, now, window)
+
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+
+	if window == TrendingSnapshotWindow {
+		setPreviousRanks(entries, now)
+	}
+
+	return entries
+}
+
+// SaveTrendingSnapshot calculates today's trending ranking and saves it as a snapshot.
+// This is meant to be run once per day as a batch job.
+func SaveTrendingSnapshot() *TrendingSnapshot {
+	now := time.Now().UTC()
+	lists, _ := AllAnimeLists()
+	entries := CalculateTrending(StreamActivityConsumeAnimes(), lists, now, TrendingSnapshotWindow)
+
+	if len(entries) > TrendingSnapshotSize {
+		entries = entries[:TrendingSnapshotSize]
+	}
+
+	snapshot := &TrendingSnapshot{
+		Date:  now.Format(trendingDateFormat),
+		Items: entries,
+	}
+
+	setPreviousRanks(entries, now)
+	snapshot.Save()
+	return snapshot
+}
+
+// CalculateTrending ranks anime by the time-decayed number of episodes watched
+// and list additions within the window before now.
+// Recent events count more, an event loses half of its weight after a quarter of the window.
+func CalculateTrending(activities chan *ActivityConsumeAnime, lists []*AnimeList, now time.Time, window time.Duration) []*TrendingEntry {
+	entries := map[string]*TrendingEntry{}
+	halfLife := float64(window) * trendingHalfLifeFraction
+
+	entry := func(animeID string) *TrendingEntry {
+		trendingEntry, exists := entries[animeID]
+
+		if !exists {
+			trendingEntry = &TrendingEntry{AnimeID: animeID}
+			entries[animeID] = trendingEntry
+		}
+
+		return trendingEntry
+	}
+
+	decay := func(date string) (float64, bool) {
+		created, err := time.Parse(time.RFC3339, date)
+
+		if err != nil {
+			return 0, false
+		}
+
+		age := now.Sub(created)
+
+		if age < 0 || age > window {
+			return 0, false
+		}
+
+		return math.Exp2(-float64(age) / halfLife), true
+	}
+
+	for activity := range activities {
+		weight, inWindow := decay(activity.Created)
+
+		if !inWindow {
+			continue
+		}
+
+		episodes := activity.ToEpisode - activity.FromEpisode + 1
+
+		if episodes < 1 {
+			continue
+		}
+
+		trendingEntry := entry(activity.AnimeID)
+		trendingEntry.Episodes += episodes
+		trendingEntry.Score += float64(episodes) * weight
+	}
+
+	for _, list := range lists {
+		list.Lock()
+
+		for _, item := range list.Items {
+			weight, inWindow := decay(item.Created)
+
+			if !inWindow {
+				continue
+			}
+
+			trendingEntry := entry(item.AnimeID)
+			trendingEntry.Additions++
+			trendingEntry.Score += trendingAdditionWeight * weight
+		}
+
+		list.Unlock()
+	}
+
+	results := make([]*TrendingEntry, 0, len(entries))
+
+	for _, trendingEntry := range entries {
+		results = append(results, trendingEntry)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].AnimeID < results[j].AnimeID
+		}
+
+		return results[i].Score > results[j].Score
+	})
+
+	for index, trendingEntry := range results {
+		trendingEntry.Rank = index + 1
+	}
+
+	return results
+}
+
+// setPreviousRanks sets the ranks of the entries in the snapshot of the day before now.
+func setPreviousRanks(entries []*TrendingEntry, now time.Time) {
+	previous, err := GetTrendingSnapshot(now.AddDate(0, 0, -1).Format(trendingDateFormat))
+
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		entry.PreviousRank = previous.Rank(entry.AnimeID)
+	}
+}
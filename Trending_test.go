@@ -0,0 +1,83 @@
+package arn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateTrending(t *testing.T) {
+	now := time.Date(2019, time.June, 8, 12, 0, 0, 0, time.UTC)
+	window := 7 * 24 * time.Hour
+	ago := func(duration time.Duration) string {
+		return now.Add(-duration).Format(time.RFC3339)
+	}
+
+	activity := func(animeID string, from int, to int, created string) *arn.ActivityConsumeAnime {
+		return &arn.ActivityConsumeAnime{
+			AnimeID:     animeID,
+			FromEpisode: from,
+			ToEpisode:   to,
+			HasCreator:  arn.HasCreator{Created: created},
+		}
+	}
+
+	activities := make(chan *arn.ActivityConsumeAnime, 10)
+	activities <- activity("binge", 1, 12, ago(6*24*time.Hour))
+	activities <- activity("weekly", 5, 5, ago(2*time.Hour))
+	activities <- activity("weekly", 6, 6, ago(3*time.Hour))
+	activities <- activity("weekly", 7, 7, ago(4*time.Hour))
+	activities <- activity("old", 1, 24, ago(8*24*time.Hour))
+	activities <- activity("future", 1, 24, now.Add(time.Hour).Format(time.RFC3339))
+	close(activities)
+
+	lists := []*arn.AnimeList{
+		{
+			UserID: "a",
+			Items: []*arn.AnimeListItem{
+				{AnimeID: "added", Created: ago(time.Hour)},
+				{AnimeID: "weekly", Created: ago(30 * 24 * time.Hour)},
+			},
+		},
+	}
+
+	entries := arn.CalculateTrending(activities, lists, now, window)
+
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "binge", entries[2].AnimeID)
+	assert.Equal(t, 12, entries[2].Episodes)
+	assert.Equal(t, "weekly", entries[0].AnimeID)
+	assert.Equal(t, 3, entries[0].Episodes)
+	assert.Equal(t, 0, entries[0].Additions)
+	assert.Equal(t, "added", entries[1].AnimeID)
+	assert.Equal(t, 1, entries[1].Additions)
+
+	for index, entry := range entries {
+		assert.Equal(t, index+1, entry.Rank)
+	}
+}
+
+func TestTrendingMovement(t *testing.T) {
+	snapshot := &arn.TrendingSnapshot{
+		Date: "2019-06-07",
+		Items: []*arn.TrendingEntry{
+			{AnimeID: "a", Rank: 1},
+			{AnimeID: "b", Rank: 2},
+		},
+	}
+
+	up := &arn.TrendingEntry{AnimeID: "b", Rank: 1, PreviousRank: snapshot.Rank("b")}
+	down := &arn.TrendingEntry{AnimeID: "a", Rank: 3, PreviousRank: snapshot.Rank("a")}
+	same := &arn.TrendingEntry{AnimeID: "a", Rank: 1, PreviousRank: snapshot.Rank("a")}
+	new := &arn.TrendingEntry{AnimeID: "c", Rank: 2, PreviousRank: snapshot.Rank("c")}
+
+	assert.Equal(t, arn.TrendUp, up.Trend())
+	assert.Equal(t, 1, up.Movement())
+	assert.Equal(t, arn.TrendDown, down.Trend())
+	assert.Equal(t, -2, down.Movement())
+	assert.Equal(t, arn.TrendSame, same.Trend())
+	assert.Equal(t, arn.TrendNew, new.Trend())
+	assert.Equal(t, 0, new.Movement())
+}
@@ -129,6 +129,9 @@ func RegisterUser(user *User) {
 	// Add empty notifications list
 	NewUserNotifications(user.ID).Save()
 
+	// Add empty watch log
+	NewWatchLog(user.ID).Save()
+
 	// Fetch gravatar
 	if user.Email != "" && !IsDevelopment() {
 		gravatarURL := gravatar.Url(user.Email) + "?s=" + fmt.Sprint(AvatarMaxSize) + "&d=404&r=pg"
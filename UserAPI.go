@@ -214,6 +214,7 @@ func (user *User) Edit(ctx *aero.Context, key string, value reflect.Value, newVa
 // Save saves the user object in the database.
 func (user *User) Save() {
 	DB.Set("User", user.ID, user)
+	notifySave("User", user)
 }
 
 // Filter removes privacy critical fields from the user object.
@@ -0,0 +1,85 @@
+package arn
+
+import "github.com/aerogo/nano"
+
+// CompatibleUsersPerUser is the number of compatible users stored for each user.
+const CompatibleUsersPerUser = 50
+
+// UserCompatibilities is the cached list of the most compatible users for a user.
+type UserCompatibilities struct {
+	UserID  string               `json:"userId"`
+	Items   []*UserCompatibility `json:"items"`
+	Created string               `json:"created"`
+}
+
+// CompatibleUsers returns the n users whose anime lists are most compatible with the user's list.
+// The compatibilities are calculated by RefreshUserCompatibilities.
+func (user *User) CompatibleUsers(n int) []*UserCompatibility {
+	compatibilities, err := GetUserCompatibilities(user.ID)
+
+	if err != nil {
+		return nil
+	}
+
+	items := compatibilities.Items
+
+	if len(items) > n {
+		items = items[:n]
+	}
+
+	return items
+}
+
+// RefreshUserCompatibilities compares all anime lists with each other
+// and stores the most compatible users for every user. This is meant to be run as a batch job.
+func RefreshUserCompatibilities() {
+	lists, _ := AllAnimeLists()
+	results := FindCompatibleUsers(lists, CompatibleUsersPerUser)
+	now := DateTimeUTC()
+
+	for _, list := range lists {
+		compatibilities := &UserCompatibilities{
+			UserID:  list.UserID,
+			Items:   results[list.UserID],
+			Created: now,
+		}
+
+		compatibilities.Save()
+	}
+}
+
+// TypeName returns the type name.
+func (compatibilities *UserCompatibilities) TypeName() string {
+	return "UserCompatibilities"
+}
+
+// Save saves the compatibilities in the database.
+func (compatibilities *UserCompatibilities) Save() {
+	DB.Set("UserCompatibilities", compatibilities.UserID, compatibilities)
+}
+
+// GetUserCompatibilities returns the cached compatibilities for the given user ID.
+func GetUserCompatibilities(userID string) (*UserCompatibilities, error) {
+	obj, err := DB.Get("UserCompatibilities", userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*UserCompatibilities), nil
+}
+
+// StreamUserCompatibilities returns a stream of all cached compatibilities.
+func StreamUserCompatibilities() chan *UserCompatibilities {
+	channel := make(chan *UserCompatibilities, nano.ChannelBufferSize)
+
+	go func() {
+		for obj := range DB.All("UserCompatibilities") {
+			channel <- obj.(*UserCompatibilities)
+		}
+
+		close(channel)
+	}()
+
+	return channel
+}
@@ -0,0 +1,275 @@
+package arn
+
+import (
+	"math"
+	"sort"
+)
+
+// Weights used for the compatibility score.
+const (
+	// correlationWeight is the share of the rating correlation in the compatibility score.
+	// The rest is determined by the status overlap.
+	correlationWeight = 0.7
+
+	// compatibilityShrinkage dampens the correlation of users with only a few co-rated anime.
+	compatibilityShrinkage = 5.0
+
+	// minCommonAnime is the number of shared anime needed to calculate a compatibility.
+	minCommonAnime = 3
+)
+
+// UserCompatibility describes how similar the anime taste of another user is.
+type UserCompatibility struct {
+	UserID          string   `json:"userId"`
+	Score           float64  `json:"score"`
+	Correlation     float64  `json:"correlation"`
+	StatusOverlap   float64  `json:"statusOverlap"`
+	CommonAnime     int      `json:"commonAnime"`
+	CoRated         int      `json:"coRated"`
+	SharedFavorites []string `json:"sharedFavorites"`
+}
+
+// User returns the compatible user.
+func (compatibility *UserCompatibility) User() *User {
+	user, _ := GetUser(compatibility.UserID)
+	return user
+}
+
+// SharedFavoriteAnime returns the anime both users rated highly.
+func (compatibility *UserCompatibility) SharedFavoriteAnime() []*Anime {
+	objects := DB.GetMany("Anime", compatibility.SharedFavorites)
+	animes := make([]*Anime, 0, len(objects))
+
+	for _, obj := range objects {
+		if obj != nil {
+			animes = append(animes, obj.(*Anime))
+		}
+	}
+
+	return animes
+}
+
+// compatibilityProfile is the normalized view on an anime list used for comparisons.
+type compatibilityProfile struct {
+	ratings  map[string]float64
+	original map[string]float64
+	statuses map[string]string
+}
+
+// AnimeListCompatibility returns the compatibility of the other list with the list.
+// The score is in the range [0, 1] and combines the Pearson correlation of the normalized
+// ratings of co-rated anime with the fraction of common anime that share the same status.
+// Returns nil if the lists don't have enough anime in common.
+func AnimeListCompatibility(list *AnimeList, other *AnimeList) *UserCompatibility {
+	return newCompatibilityProfile(list).compare(newCompatibilityProfile(other), other.UserID)
+}
+
+// FindCompatibleUsers returns the n most compatible users for every list.
+// Only lists sharing at least minCommonAnime anime are compared, the candidates
+// are found via an inverted index from anime to lists.
+func FindCompatibleUsers(lists []*AnimeList, n int) map[string][]*UserCompatibility {
+	profiles := make([]*compatibilityProfile, len(lists))
+	watchers := map[string][]int{}
+
+	for index, list := range lists {
+		profiles[index] = newCompatibilityProfile(list)
+
+		for animeID := range profiles[index].statuses {
+			watchers[animeID] = append(watchers[animeID], index)
+		}
+	}
+
+	results := make(map[string][]*UserCompatibility, len(lists))
+
+	for index, profile := range profiles {
+		commonAnime := map[int]int{}
+
+		for animeID := range profile.statuses {
+			for _, other := range watchers[animeID] {
+				commonAnime[other]++
+			}
+		}
+
+		var compatibilities []*UserCompatibility
+
+		for other, count := range commonAnime {
+			if other == index || count < minCommonAnime || lists[other].UserID == lists[index].UserID {
+				continue
+			}
+
+			compatibility := profile.compare(profiles[other], lists[other].UserID)
+
+			if compatibility != nil {
+				compatibilities = append(compatibilities, compatibility)
+			}
+		}
+
+		SortUserCompatibilities(compatibilities)
+
+		if len(compatibilities) > n {
+			compatibilities = compatibilities[:n]
+		}
+
+		results[lists[index].UserID] = compatibilities
+	}
+
+	return results
+}
+
+// SortUserCompatibilities sorts the compatibilities by score, highest first.
+func SortUserCompatibilities(compatibilities []*UserCompatibility) {
+	sort.Slice(compatibilities, func(i, j int) bool {
+		a := compatibilities[i]
+		b := compatibilities[j]
+
+		if a.Score == b.Score {
+			return a.UserID < b.UserID
+		}
+
+		return a.Score > b.Score
+	})
+}
+
+// newCompatibilityProfile normalizes a copy of the list so that the original stays untouched.
+// Private items are ignored.
+func newCompatibilityProfile(list *AnimeList) *compatibilityProfile {
+	profile := &compatibilityProfile{
+		ratings:  map[string]float64{},
+		original: map[string]float64{},
+		statuses: map[string]string{},
+	}
+
+	normalized := &AnimeList{UserID: list.UserID}
+
+	list.Lock()
+
+	for _, item := range list.Items {
+		if item.Private {
+			continue
+		}
+
+		if item.Rating.Overall != 0 {
+			profile.original[item.AnimeID] = item.Rating.Overall
+		}
+
+		itemCopy := *item
+		normalized.Items = append(normalized.Items, &itemCopy)
+	}
+
+	list.Unlock()
+
+	normalized.NormalizeRatings()
+
+	for _, item := range normalized.Items {
+		profile.statuses[item.AnimeID] = item.Status
+
+		if item.Rating.Overall != 0 {
+			profile.ratings[item.AnimeID] = item.Rating.Overall
+		}
+	}
+
+	return profile
+}
+
+// compare calculates the compatibility of the other profile.
+func (profile *compatibilityProfile) compare(other *compatibilityProfile, otherUserID string) *UserCompatibility {
+	commonAnime := 0
+	sameStatus := 0
+
+	for animeID, status := range profile.statuses {
+		otherStatus, exists := other.statuses[animeID]
+
+		if !exists {
+			continue
+		}
+
+		commonAnime++
+
+		if status == otherStatus {
+			sameStatus++
+		}
+	}
+
+	if commonAnime < minCommonAnime {
+		return nil
+	}
+
+	var coRated []string
+
+	for animeID := range profile.ratings {
+		if _, exists := other.ratings[animeID]; exists {
+			coRated = append(coRated, animeID)
+		}
+	}
+
+	sort.Strings(coRated)
+
+	correlation := pearsonCorrelation(coRated, profile.ratings, other.ratings)
+	confidence := float64(len(coRated)) / (float64(len(coRated)) + compatibilityShrinkage)
+	statusOverlap := float64(sameStatus) / float64(commonAnime)
+
+	return &UserCompatibility{
+		UserID:          otherUserID,
+		Score:           correlationWeight*(correlation*confidence+1)/2 + (1-correlationWeight)*statusOverlap,
+		Correlation:     correlation,
+		StatusOverlap:   statusOverlap,
+		CommonAnime:     commonAnime,
+		CoRated:         len(coRated),
+		SharedFavorites: sharedFavorites(coRated, profile.original, other.original),
+	}
+}
+
+// pearsonCorrelation returns the correlation of the ratings for the given anime IDs.
+// Returns 0 if there are not enough ratings or one of the users rated everything the same.
+func pearsonCorrelation(animeIDs []string, a map[string]float64, b map[string]float64) float64 {
+	if len(animeIDs) < 2 {
+		return 0
+	}
+
+	meanA := 0.0
+	meanB := 0.0
+
+	for _, animeID := range animeIDs {
+		meanA += a[animeID]
+		meanB += b[animeID]
+	}
+
+	meanA /= float64(len(animeIDs))
+	meanB /= float64(len(animeIDs))
+
+	covariance := 0.0
+	varianceA := 0.0
+	varianceB := 0.0
+
+	for _, animeID := range animeIDs {
+		deltaA := a[animeID] - meanA
+		deltaB := b[animeID] - meanB
+		covariance += deltaA * deltaB
+		varianceA += deltaA * deltaA
+		varianceB += deltaB * deltaB
+	}
+
+	if varianceA == 0 || varianceB == 0 {
+		return 0
+	}
+
+	return covariance / math.Sqrt(varianceA*varianceB)
+}
+
+// sharedFavorites returns the anime that both users rated highly, best combined rating first.
+// The original ratings are used because normalized ratings depend on the rest of the list.
+func sharedFavorites(animeIDs []string, a map[string]float64, b map[string]float64) []string {
+	favorites := []string{}
+
+	for _, animeID := range animeIDs {
+		if a[animeID] >= highRatingThreshold && b[animeID] >= highRatingThreshold {
+			favorites = append(favorites, animeID)
+		}
+	}
+
+	sort.SliceStable(favorites, func(i, j int) bool {
+		return a[favorites[i]]+b[favorites[i]] > a[favorites[j]]+b[favorites[j]]
+	})
+
+	return favorites
+}
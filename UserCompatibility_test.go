@@ -0,0 +1,56 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnimeListCompatibility(t *testing.T) {
+	list := newTestList("a", map[string]float64{"steins-gate": 10, "erased": 9, "clannad": 3, "k-on": 5})
+	similar := newTestList("b", map[string]float64{"steins-gate": 9, "erased": 8, "clannad": 2, "k-on": 4})
+	opposite := newTestList("c", map[string]float64{"steins-gate": 2, "erased": 4, "clannad": 9, "k-on": 7})
+	unrelated := newTestList("d", map[string]float64{"steins-gate": 8, "one-piece": 9})
+
+	good := arn.AnimeListCompatibility(list, similar)
+	bad := arn.AnimeListCompatibility(list, opposite)
+
+	assert.Equal(t, "b", good.UserID)
+	assert.Equal(t, 4, good.CommonAnime)
+	assert.Equal(t, 4, good.CoRated)
+	assert.InDelta(t, 1.0, good.Correlation, 0.0001)
+	assert.Equal(t, 1.0, good.StatusOverlap)
+	assert.Equal(t, []string{"steins-gate", "erased"}, good.SharedFavorites)
+	assert.True(t, bad.Correlation < 0)
+	assert.Empty(t, bad.SharedFavorites)
+	assert.True(t, good.Score > bad.Score)
+	assert.True(t, good.Score <= 1)
+	assert.True(t, bad.Score >= 0)
+	assert.Nil(t, arn.AnimeListCompatibility(list, unrelated))
+
+	// The original ratings must not be modified by the normalization
+	assert.Equal(t, 9.0, similar.Find("steins-gate").Rating.Overall)
+
+	compatibilities := []*arn.UserCompatibility{bad, good}
+	arn.SortUserCompatibilities(compatibilities)
+	assert.Equal(t, "b", compatibilities[0].UserID)
+}
+
+func TestFindCompatibleUsers(t *testing.T) {
+	lists := []*arn.AnimeList{
+		newTestList("a", map[string]float64{"steins-gate": 10, "erased": 9, "clannad": 3, "k-on": 5}),
+		newTestList("b", map[string]float64{"steins-gate": 9, "erased": 8, "clannad": 2, "k-on": 4}),
+		newTestList("c", map[string]float64{"steins-gate": 2, "erased": 4, "clannad": 9}),
+		newTestList("d", map[string]float64{"steins-gate": 8, "one-piece": 9, "naruto": 7}),
+	}
+
+	results := arn.FindCompatibleUsers(lists, 1)
+
+	assert.Len(t, results["a"], 1)
+	assert.Equal(t, "b", results["a"][0].UserID)
+	assert.Equal(t, "a", results["c"][0].UserID)
+
+	// Lists with less than minCommonAnime shared anime are never compared
+	assert.Empty(t, results["d"])
+}
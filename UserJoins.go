@@ -30,6 +30,12 @@ func (user *User) AnimeList() *AnimeList {
 	return animeList
 }
 
+// WatchLog returns the history of watched episodes.
+func (user *User) WatchLog() *WatchLog {
+	log, _ := GetWatchLog(user.ID)
+	return log
+}
+
 // PushSubscriptions ...
 func (user *User) PushSubscriptions() *PushSubscriptions {
 	subs, _ := GetPushSubscriptions(user.ID)
@@ -0,0 +1,289 @@
+package arn
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aerogo/nano"
+)
+
+// maxWatchLogEntries limits the size of a watch log.
+// The oldest entries are removed when the limit is exceeded.
+const maxWatchLogEntries = 20000
+
+// WatchLog is the append-only history of all episodes a user watched.
+type WatchLog struct {
+	UserID string           `json:"userId"`
+	Items  []*WatchLogEntry `json:"items"`
+
+	sync.Mutex
+}
+
+// WatchLogEntry is a single watched episode.
+// The rewatch index is 0 for the first time an episode was watched, 1 for the first rewatch and so on.
+type WatchLogEntry struct {
+	AnimeID string `json:"animeId"`
+	Episode int    `json:"episode"`
+	Rewatch int    `json:"rewatch"`
+	Created string `json:"created"`
+}
+
+// CreatedTime returns the time the episode was watched.
+func (entry *WatchLogEntry) CreatedTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, entry.Created)
+	return t
+}
+
+// NewWatchLog creates a new empty watch log.
+func NewWatchLog(userID string) *WatchLog {
+	return &WatchLog{
+		UserID: userID,
+		Items:  []*WatchLogEntry{},
+	}
+}
+
+// Append adds an entry for every episode in the range [fromEpisode, toEpisode].
+// The rewatch index is derived from how often the episode has been watched before.
+func (log *WatchLog) Append(animeID string, fromEpisode int, toEpisode int, created string) {
+	log.Lock()
+	defer log.Unlock()
+
+	if fromEpisode < 1 {
+		fromEpisode = 1
+	}
+
+	if toEpisode < fromEpisode {
+		return
+	}
+
+	watched := log.watched(animeID)
+
+	for episode := fromEpisode; episode <= toEpisode; episode++ {
+		log.Items = append(log.Items, &WatchLogEntry{
+			AnimeID: animeID,
+			Episode: episode,
+			Rewatch: watched[episode],
+			Created: created,
+		})
+	}
+
+	log.trim()
+}
+
+// AppendProgress adds the episodes watched between the previous and the current state of a list item.
+// Every increase of the rewatch count adds the episodes that haven't been logged for that rewatch yet.
+func (log *WatchLog) AppendProgress(previous *AnimeListItem, current *AnimeListItem, created string) {
+	if current.Episodes > previous.Episodes {
+		log.Append(current.AnimeID, previous.Episodes+1, current.Episodes, created)
+	}
+
+	if current.RewatchCount <= previous.RewatchCount {
+		return
+	}
+
+	log.Lock()
+	defer log.Unlock()
+
+	watched := log.watched(current.AnimeID)
+
+	for rewatch := previous.RewatchCount + 1; rewatch <= current.RewatchCount; rewatch++ {
+		for episode := 1; episode <= current.Episodes; episode++ {
+			if watched[episode] > rewatch {
+				continue
+			}
+
+			log.Items = append(log.Items, &WatchLogEntry{
+				AnimeID: current.AnimeID,
+				Episode: episode,
+				Rewatch: watched[episode],
+				Created: created,
+			})
+
+			watched[episode]++
+		}
+	}
+
+	log.trim()
+}
+
+// watched returns how often each episode of the anime has been watched.
+// The log must be locked by the caller.
+func (log *WatchLog) watched(animeID string) map[int]int {
+	watched := map[int]int{}
+
+	for _, entry := range log.Items {
+		if entry.AnimeID == animeID && entry.Rewatch >= watched[entry.Episode] {
+			watched[entry.Episode] = entry.Rewatch + 1
+		}
+	}
+
+	return watched
+}
+
+// trim removes the oldest entries if the log exceeds maxWatchLogEntries.
+// The log must be locked by the caller.
+func (log *WatchLog) trim() {
+	if len(log.Items) <= maxWatchLogEntries {
+		return
+	}
+
+	items := make([]*WatchLogEntry, maxWatchLogEntries)
+	copy(items, log.Items[len(log.Items)-maxWatchLogEntries:])
+	log.Items = items
+}
+
+// Entries returns all entries for the given anime in chronological order.
+func (log *WatchLog) Entries(animeID string) []*WatchLogEntry {
+	log.Lock()
+	defer log.Unlock()
+
+	var entries []*WatchLogEntry
+
+	for _, entry := range log.Items {
+		if entry.AnimeID == animeID {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Created < entries[j].Created
+	})
+
+	return entries
+}
+
+// EpisodesPerDay returns the number of watched episodes mapped to the date in the given location.
+// The dates are formatted as "2006-01-02".
+func (log *WatchLog) EpisodesPerDay(location *time.Location) map[string]int {
+	log.Lock()
+	defer log.Unlock()
+
+	days := map[string]int{}
+
+	for _, entry := range log.Items {
+		created := entry.CreatedTime()
+
+		if created.IsZero() {
+			continue
+		}
+
+		days[created.In(location).Format("2006-01-02")]++
+	}
+
+	return days
+}
+
+// TimeToComplete returns the time between watching the first and the last episode
+// of the anime for the first time. The second return value is false if the anime
+// hasn't been completed yet or the number of episodes is unknown.
+func (log *WatchLog) TimeToComplete(animeID string, episodeCount int) (time.Duration, bool) {
+	if episodeCount <= 0 {
+		return 0, false
+	}
+
+	var start, end time.Time
+
+	for _, entry := range log.Entries(animeID) {
+		if entry.Rewatch != 0 {
+			continue
+		}
+
+		if entry.Episode == 1 && start.IsZero() {
+			start = entry.CreatedTime()
+		}
+
+		if entry.Episode == episodeCount && end.IsZero() {
+			end = entry.CreatedTime()
+		}
+	}
+
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0, false
+	}
+
+	return end.Sub(start), true
+}
+
+// RewatchCount reconstructs how often the anime has been rewatched completely.
+func (log *WatchLog) RewatchCount(animeID string, episodeCount int) int {
+	if episodeCount <= 0 {
+		return 0
+	}
+
+	count := 0
+
+	for _, entry := range log.Entries(animeID) {
+		if entry.Episode == episodeCount && entry.Rewatch > count {
+			count = entry.Rewatch
+		}
+	}
+
+	return count
+}
+
+// TypeName returns the type name.
+func (log *WatchLog) TypeName() string {
+	return "WatchLog"
+}
+
+// GetID returns the user ID.
+func (log *WatchLog) GetID() string {
+	return log.UserID
+}
+
+// Save saves the watch log in the database.
+func (log *WatchLog) Save() {
+	DB.Set("WatchLog", log.UserID, log)
+}
+
+// UpdateWatchLog passes the user's watch log to the update function and saves it.
+// The watch log is created if it doesn't exist yet.
+func UpdateWatchLog(userID string, update func(*WatchLog)) {
+	log, err := GetWatchLog(userID)
+
+	if err != nil {
+		log = NewWatchLog(userID)
+	}
+
+	update(log)
+	log.Save()
+}
+
+// logWatchProgress adds the episodes watched between the previous and the current state
+// of the list item to the user's watch log.
+func logWatchProgress(userID string, previous *AnimeListItem, current *AnimeListItem) {
+	if current.Episodes <= previous.Episodes && current.RewatchCount <= previous.RewatchCount {
+		return
+	}
+
+	UpdateWatchLog(userID, func(log *WatchLog) {
+		log.AppendProgress(previous, current, DateTimeUTC())
+	})
+}
+
+// GetWatchLog returns the watch log for the given user ID.
+func GetWatchLog(userID string) (*WatchLog, error) {
+	obj, err := DB.Get("WatchLog", userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*WatchLog), nil
+}
+
+// StreamWatchLogs returns a stream of all watch logs.
+func StreamWatchLogs() chan *WatchLog {
+	channel := make(chan *WatchLog, nano.ChannelBufferSize)
+
+	go func() {
+		for obj := range DB.All("WatchLog") {
+			channel <- obj.(*WatchLog)
+		}
+
+		close(channel)
+	}()
+
+	return channel
+}
@@ -0,0 +1,88 @@
+package arn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchLog(t *testing.T) {
+	log := arn.NewWatchLog("4J6qpK1ve")
+	log.Append("erased", 1, 3, "2019-01-01T20:00:00Z")
+	log.Append("erased", 4, 12, "2019-01-02T23:30:00Z")
+	log.Append("k-on", 0, 1, "2019-01-03T10:00:00Z")
+
+	// Rewatch of the first half
+	log.Append("erased", 1, 6, "2019-02-01T20:00:00Z")
+
+	entries := log.Entries("erased")
+	assert.Len(t, entries, 18)
+	assert.Equal(t, 1, entries[0].Episode)
+	assert.Equal(t, 0, entries[0].Rewatch)
+	assert.Equal(t, 1, entries[12].Episode)
+	assert.Equal(t, 1, entries[12].Rewatch)
+
+	days := log.EpisodesPerDay(time.UTC)
+	assert.Equal(t, map[string]int{"2019-01-01": 3, "2019-01-02": 9, "2019-01-03": 1, "2019-02-01": 6}, days)
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	days = log.EpisodesPerDay(tokyo)
+	assert.Equal(t, 10, days["2019-01-03"])
+
+	duration, completed := log.TimeToComplete("erased", 12)
+	assert.True(t, completed)
+	assert.Equal(t, 27*time.Hour+30*time.Minute, duration)
+
+	_, completed = log.TimeToComplete("k-on", 13)
+	assert.False(t, completed)
+
+	_, completed = log.TimeToComplete("erased", 0)
+	assert.False(t, completed)
+
+	assert.Equal(t, 0, log.RewatchCount("erased", 12))
+	log.Append("erased", 7, 12, "2019-02-02T20:00:00Z")
+	assert.Equal(t, 1, log.RewatchCount("erased", 12))
+}
+
+func TestWatchLogAppendProgress(t *testing.T) {
+	log := arn.NewWatchLog("4J6qpK1ve")
+	log.Append("erased", 1, 10, "2019-01-01T18:00:00Z")
+	item := &arn.AnimeListItem{AnimeID: "erased", Episodes: 10}
+
+	// Completing via a status change
+	completed := *item
+	completed.Episodes = 12
+	log.AppendProgress(item, &completed, "2019-01-01T20:00:00Z")
+	assert.Len(t, log.Entries("erased"), 12)
+
+	// Rewatching the episodes one by one and increasing the rewatch count afterwards
+	log.Append("erased", 1, 12, "2019-02-01T20:00:00Z")
+	rewatched := completed
+	rewatched.RewatchCount = 1
+	log.AppendProgress(&completed, &rewatched, "2019-02-01T21:00:00Z")
+	assert.Len(t, log.Entries("erased"), 24)
+
+	// Increasing the rewatch count without the episodes being logged
+	twice := rewatched
+	twice.RewatchCount = 2
+	log.AppendProgress(&rewatched, &twice, "2019-03-01T20:00:00Z")
+	assert.Len(t, log.Entries("erased"), 36)
+	assert.Equal(t, 2, log.RewatchCount("erased", 12))
+
+	// Nothing is logged when the progress decreases
+	log.AppendProgress(&twice, item, "2019-04-01T20:00:00Z")
+	assert.Len(t, log.Entries("erased"), 36)
+}
+
+func TestWatchLogLimit(t *testing.T) {
+	log := arn.NewWatchLog("4J6qpK1ve")
+	log.Append("one-piece", 1, 15000, "2019-01-01T20:00:00Z")
+	log.Append("one-piece", 1, 10000, "2019-02-01T20:00:00Z")
+
+	entries := log.Entries("one-piece")
+	assert.Len(t, entries, 20000)
+	assert.Equal(t, 5001, entries[0].Episode)
+	assert.Equal(t, 1, entries[10000].Rewatch)
+}
@@ -0,0 +1,419 @@
+package arn
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aerogo/nano"
+)
+
+// yearInReviewTopRated is the number of top rated anime included in the report.
+const yearInReviewTopRated = 5
+
+// YearInReview is the yearly watch report of a user.
+type YearInReview struct {
+	UserID             string                `json:"userId"`
+	Year               int                   `json:"year"`
+	Episodes           int                   `json:"episodes"`
+	Minutes            int                   `json:"minutes"`
+	Anime              int                   `json:"anime"`
+	CompletedAnime     []string              `json:"completedAnime"`
+	Genres             map[string]float64    `json:"genres"`
+	Studios            map[string]float64    `json:"studios"`
+	TopRated           []*YearInReviewAnime  `json:"topRated"`
+	LongestStreak      int                   `json:"longestStreak"`
+	LongestStreakStart string                `json:"longestStreakStart"`
+	BusiestDay         string                `json:"busiestDay"`
+	BusiestDayEpisodes int                   `json:"busiestDayEpisodes"`
+	Community          *YearInReviewAverages `json:"community"`
+}
+
+// YearInReviewAnime is a rated anime in the yearly report.
+type YearInReviewAnime struct {
+	AnimeID string  `json:"animeId"`
+	Rating  float64 `json:"rating"`
+}
+
+// Anime returns the anime.
+func (item *YearInReviewAnime) Anime() *Anime {
+	anime, _ := GetAnime(item.AnimeID)
+	return anime
+}
+
+// YearInReviewAverages contains the averages of all users who watched anything in the year.
+// The averages are stored as a snapshot per year, see SaveYearInReviewAverages.
+type YearInReviewAverages struct {
+	Year     int     `json:"year"`
+	Users    int     `json:"users"`
+	Episodes float64 `json:"episodes"`
+	Minutes  float64 `json:"minutes"`
+}
+
+// NewYearInReview creates the report for the given year from the anime list and the watch log.
+// The anime map needs to contain all anime that appear in the watch log.
+// Dates are calculated in UTC.
+func NewYearInReview(list *AnimeList, log *WatchLog, animes map[string]*Anime, year int) *YearInReview {
+	report := &YearInReview{
+		UserID:         list.UserID,
+		Year:           year,
+		CompletedAnime: []string{},
+		Genres:         map[string]float64{},
+		Studios:        map[string]float64{},
+		TopRated:       []*YearInReviewAnime{},
+	}
+
+	watched := map[string]bool{}
+	days := map[string]int{}
+
+	for _, entry := range yearEntries(log, year) {
+		anime := animes[entry.AnimeID]
+		day := entry.CreatedTime().UTC().Format("2006-01-02")
+
+		report.Episodes++
+		days[day]++
+		watched[entry.AnimeID] = true
+
+		if anime == nil {
+			continue
+		}
+
+		report.Minutes += anime.EpisodeLength
+
+		for _, genre := range anime.Genres {
+			report.Genres[genre]++
+		}
+
+		for _, studioID := range anime.StudioIDs {
+			report.Studios[studioID]++
+		}
+
+		if entry.Episode == anime.EpisodeCount && !Contains(report.CompletedAnime, entry.AnimeID) {
+			report.CompletedAnime = append(report.CompletedAnime, entry.AnimeID)
+		}
+	}
+
+	report.Anime = len(watched)
+	report.setDays(days)
+	report.setTopRated(list, watched)
+	return report
+}
+
+// Hours returns the number of hours watched.
+func (report *YearInReview) Hours() float64 {
+	return float64(report.Minutes) / 60
+}
+
+// EpisodesComparedToCommunity returns the ratio of the user's episodes to the community average.
+// Returns 0 if the community average is unknown.
+func (report *YearInReview) EpisodesComparedToCommunity() float64 {
+	if report.Community == nil || report.Community.Episodes == 0 {
+		return 0
+	}
+
+	return float64(report.Episodes) / report.Community.Episodes
+}
+
+// PieCharts returns the genre and studio breakdowns as pie charts.
+func (report *YearInReview) PieCharts() []*PieChart {
+	studios := map[string]float64{}
+	studioIDs := make([]string, 0, len(report.Studios))
+
+	for studioID := range report.Studios {
+		studioIDs = append(studioIDs, studioID)
+	}
+
+	for index, obj := range DB.GetMany("Company", studioIDs) {
+		name := studioIDs[index]
+
+		if obj != nil {
+			name = obj.(*Company).Name.English
+		}
+
+		studios[name] += report.Studios[studioIDs[index]]
+	}
+
+	return []*PieChart{
+		NewPieChart("Genres", report.Genres),
+		NewPieChart("Studios", studios),
+	}
+}
+
+// StatisticsCategory returns the report as a statistics category.
+func (report *YearInReview) StatisticsCategory() *StatisticsCategory {
+	return &StatisticsCategory{
+		Name:      fmt.Sprintf("%d in review", report.Year),
+		PieCharts: report.PieCharts(),
+	}
+}
+
+// setDays calculates the longest streak of consecutive days and the busiest day.
+func (report *YearInReview) setDays(days map[string]int) {
+	dates := make([]string, 0, len(days))
+
+	for day, episodes := range days {
+		dates = append(dates, day)
+
+		if episodes > report.BusiestDayEpisodes || (episodes == report.BusiestDayEpisodes && day < report.BusiestDay) {
+			report.BusiestDay = day
+			report.BusiestDayEpisodes = episodes
+		}
+	}
+
+	sort.Strings(dates)
+
+	streak := 0
+	streakStart := ""
+	var previous time.Time
+
+	for _, day := range dates {
+		date, _ := time.Parse("2006-01-02", day)
+
+		if streak > 0 && date.Sub(previous) == 24*time.Hour {
+			streak++
+		} else {
+			streak = 1
+			streakStart = day
+		}
+
+		if streak > report.LongestStreak {
+			report.LongestStreak = streak
+			report.LongestStreakStart = streakStart
+		}
+
+		previous = date
+	}
+}
+
+// setTopRated finds the best rated anime that were watched in the year.
+func (report *YearInReview) setTopRated(list *AnimeList, watched map[string]bool) {
+	list.Lock()
+
+	for _, item := range list.Items {
+		if !watched[item.AnimeID] || item.Rating.Overall == 0 {
+			continue
+		}
+
+		report.TopRated = append(report.TopRated, &YearInReviewAnime{
+			AnimeID: item.AnimeID,
+			Rating:  item.Rating.Overall,
+		})
+	}
+
+	list.Unlock()
+
+	sort.Slice(report.TopRated, func(i, j int) bool {
+		if report.TopRated[i].Rating == report.TopRated[j].Rating {
+			return report.TopRated[i].AnimeID < report.TopRated[j].AnimeID
+		}
+
+		return report.TopRated[i].Rating > report.TopRated[j].Rating
+	})
+
+	if len(report.TopRated) > yearInReviewTopRated {
+		report.TopRated = report.TopRated[:yearInReviewTopRated]
+	}
+}
+
+// CalculateYearInReviewAverages returns the community averages for the given year.
+// Only users who watched at least one episode in that year are taken into account.
+func CalculateYearInReviewAverages(logs []*WatchLog, animes map[string]*Anime, year int) *YearInReviewAverages {
+	averages := &YearInReviewAverages{Year: year}
+
+	for _, log := range logs {
+		entries := yearEntries(log, year)
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		averages.Users++
+		averages.Episodes += float64(len(entries))
+
+		for _, entry := range entries {
+			anime := animes[entry.AnimeID]
+
+			if anime != nil {
+				averages.Minutes += float64(anime.EpisodeLength)
+			}
+		}
+	}
+
+	if averages.Users > 0 {
+		averages.Episodes /= float64(averages.Users)
+		averages.Minutes /= float64(averages.Users)
+	}
+
+	return averages
+}
+
+// SaveYearInReviewAverages calculates the community averages of the year from all watch logs
+// and saves them as a snapshot. This is meant to be run as a batch job.
+func SaveYearInReviewAverages(year int) *YearInReviewAverages {
+	activities := map[string][]*ActivityConsumeAnime{}
+
+	for activity := range StreamActivityConsumeAnimes() {
+		activities[activity.CreatedBy] = append(activities[activity.CreatedBy], activity)
+	}
+
+	var logs []*WatchLog
+
+	for log := range StreamWatchLogs() {
+		logs = append(logs, BackfillWatchLog(log, activities[log.UserID]))
+		delete(activities, log.UserID)
+	}
+
+	// Users without a watch log only have their activities
+	for userID, userActivities := range activities {
+		logs = append(logs, BackfillWatchLog(NewWatchLog(userID), userActivities))
+	}
+
+	animes := map[string]*Anime{}
+
+	for anime := range StreamAnime() {
+		animes[anime.ID] = anime
+	}
+
+	averages := CalculateYearInReviewAverages(logs, animes, year)
+	averages.Save()
+	return averages
+}
+
+// BackfillWatchLog returns a copy of the watch log that includes the episodes of the activities
+// which happened before the first entry of the log, when the watch log didn't exist yet.
+func BackfillWatchLog(log *WatchLog, activities []*ActivityConsumeAnime) *WatchLog {
+	log.Lock()
+	items := make([]*WatchLogEntry, len(log.Items))
+	copy(items, log.Items)
+	log.Unlock()
+
+	start := ""
+
+	for _, entry := range items {
+		if start == "" || entry.Created < start {
+			start = entry.Created
+		}
+	}
+
+	sorted := make([]*ActivityConsumeAnime, len(activities))
+	copy(sorted, activities)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Created < sorted[j].Created
+	})
+
+	backfilled := NewWatchLog(log.UserID)
+
+	for _, activity := range sorted {
+		if start != "" && activity.Created >= start {
+			break
+		}
+
+		backfilled.Append(activity.AnimeID, activity.FromEpisode, activity.ToEpisode, activity.Created)
+	}
+
+	backfilled.Items = append(backfilled.Items, items...)
+	return backfilled
+}
+
+// YearInReview creates the yearly report for the user including the community averages.
+// The community averages are only included if a snapshot for the year exists.
+func (user *User) YearInReview(year int) (*YearInReview, error) {
+	list, err := GetAnimeList(user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := GetWatchLog(user.ID)
+
+	if err != nil {
+		log = NewWatchLog(user.ID)
+	}
+
+	activities := FilterActivitiesConsumeAnime(func(activity *ActivityConsumeAnime) bool {
+		return activity.CreatedBy == user.ID
+	})
+
+	log = BackfillWatchLog(log, activities)
+
+	// Only the anime in the user's log are needed
+	var animeIDs []string
+
+	for _, entry := range yearEntries(log, year) {
+		if !Contains(animeIDs, entry.AnimeID) {
+			animeIDs = append(animeIDs, entry.AnimeID)
+		}
+	}
+
+	animes := map[string]*Anime{}
+
+	for _, obj := range DB.GetMany("Anime", animeIDs) {
+		if obj != nil {
+			anime := obj.(*Anime)
+			animes[anime.ID] = anime
+		}
+	}
+
+	report := NewYearInReview(list, log, animes, year)
+	report.Community, _ = GetYearInReviewAverages(year)
+	return report, nil
+}
+
+// GetID returns the year of the averages.
+func (averages *YearInReviewAverages) GetID() string {
+	return strconv.Itoa(averages.Year)
+}
+
+// TypeName returns the type name.
+func (averages *YearInReviewAverages) TypeName() string {
+	return "YearInReviewAverages"
+}
+
+// Save saves the averages in the database.
+func (averages *YearInReviewAverages) Save() {
+	DB.Set("YearInReviewAverages", averages.GetID(), averages)
+}
+
+// GetYearInReviewAverages returns the community averages snapshot for the given year.
+func GetYearInReviewAverages(year int) (*YearInReviewAverages, error) {
+	obj, err := DB.Get("YearInReviewAverages", strconv.Itoa(year))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.(*YearInReviewAverages), nil
+}
+
+// StreamYearInReviewAverages returns a stream of all community averages snapshots.
+func StreamYearInReviewAverages() chan *YearInReviewAverages {
+	channel := make(chan *YearInReviewAverages, nano.ChannelBufferSize)
+
+	go func() {
+		for obj := range DB.All("YearInReviewAverages") {
+			channel <- obj.(*YearInReviewAverages)
+		}
+
+		close(channel)
+	}()
+
+	return channel
+}
+
+// yearEntries returns the watch log entries of the given year in UTC.
+func yearEntries(log *WatchLog, year int) []*WatchLogEntry {
+	log.Lock()
+	defer log.Unlock()
+
+	var entries []*WatchLogEntry
+
+	for _, entry := range log.Items {
+		if entry.CreatedTime().UTC().Year() == year {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,93 @@
+package arn_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYearInReview(t *testing.T) {
+	animes := map[string]*arn.Anime{
+		"erased": {HasID: arn.HasID{ID: "erased"}, EpisodeCount: 12, EpisodeLength: 24, Genres: []string{"Mystery", "Drama"}, StudioIDs: []string{"a1"}},
+		"k-on":   {HasID: arn.HasID{ID: "k-on"}, EpisodeCount: 13, EpisodeLength: 24, Genres: []string{"Music"}, StudioIDs: []string{"kyoani"}},
+	}
+
+	list := newTestList("4J6qpK1ve", map[string]float64{"erased": 9, "k-on": 7, "clannad": 10})
+
+	log := arn.NewWatchLog("4J6qpK1ve")
+	log.Append("erased", 1, 2, "2018-12-31T20:00:00Z")
+	log.Append("erased", 3, 6, "2019-01-01T20:00:00Z")
+	log.Append("erased", 7, 12, "2019-01-02T20:00:00Z")
+	log.Append("k-on", 1, 1, "2019-01-03T20:00:00Z")
+	log.Append("k-on", 2, 3, "2019-03-10T20:00:00Z")
+
+	report := arn.NewYearInReview(list, log, animes, 2019)
+
+	assert.Equal(t, 2019, report.Year)
+	assert.Equal(t, 13, report.Episodes)
+	assert.Equal(t, 13*24, report.Minutes)
+	assert.Equal(t, 5.2, report.Hours())
+	assert.Equal(t, 2, report.Anime)
+	assert.Equal(t, []string{"erased"}, report.CompletedAnime)
+	assert.Equal(t, 10.0, report.Genres["Mystery"])
+	assert.Equal(t, 3.0, report.Genres["Music"])
+	assert.Equal(t, 3.0, report.Studios["kyoani"])
+	assert.Equal(t, 3, report.LongestStreak)
+	assert.Equal(t, "2019-01-01", report.LongestStreakStart)
+	assert.Equal(t, "2019-01-02", report.BusiestDay)
+	assert.Equal(t, 6, report.BusiestDayEpisodes)
+	assert.Len(t, report.TopRated, 2)
+	assert.Equal(t, "erased", report.TopRated[0].AnimeID)
+
+	other := arn.NewWatchLog("other")
+	other.Append("k-on", 1, 13, "2019-05-01T10:00:00Z")
+	inactive := arn.NewWatchLog("inactive")
+
+	averages := arn.CalculateYearInReviewAverages([]*arn.WatchLog{log, other, inactive}, animes, 2019)
+	assert.Equal(t, 2, averages.Users)
+	assert.Equal(t, 13.0, averages.Episodes)
+	assert.Equal(t, 13.0*24, averages.Minutes)
+
+	report.Community = averages
+	assert.Equal(t, 1.0, report.EpisodesComparedToCommunity())
+}
+
+func TestBackfillWatchLog(t *testing.T) {
+	log := arn.NewWatchLog("4J6qpK1ve")
+	log.Append("erased", 5, 12, "2019-01-01T20:00:00Z")
+
+	newActivity := func(animeID string, fromEpisode int, toEpisode int, created string) *arn.ActivityConsumeAnime {
+		return &arn.ActivityConsumeAnime{
+			AnimeID:     animeID,
+			FromEpisode: fromEpisode,
+			ToEpisode:   toEpisode,
+			HasCreator:  arn.HasCreator{Created: created, CreatedBy: "4J6qpK1ve"},
+		}
+	}
+
+	activities := []*arn.ActivityConsumeAnime{
+		// Already part of the watch log
+		newActivity("erased", 5, 12, "2019-01-01T20:00:00Z"),
+		newActivity("erased", 1, 4, "2018-12-30T20:00:00Z"),
+		newActivity("k-on", 1, 13, "2017-05-01T10:00:00Z"),
+	}
+
+	backfilled := arn.BackfillWatchLog(log, activities)
+	assert.Len(t, log.Items, 8)
+	assert.Len(t, backfilled.Entries("erased"), 12)
+	assert.Equal(t, "2018-12-30T20:00:00Z", backfilled.Entries("erased")[0].Created)
+	assert.Len(t, backfilled.Entries("k-on"), 13)
+
+	animes := map[string]*arn.Anime{
+		"k-on": {HasID: arn.HasID{ID: "k-on"}, EpisodeCount: 13, EpisodeLength: 24},
+	}
+
+	list := newTestList("4J6qpK1ve", map[string]float64{"k-on": 7})
+	report := arn.NewYearInReview(list, backfilled, animes, 2017)
+	assert.Equal(t, 13, report.Episodes)
+	assert.Equal(t, []string{"k-on"}, report.CompletedAnime)
+
+	// Without a watch log all activities are used
+	assert.Len(t, arn.BackfillWatchLog(arn.NewWatchLog("4J6qpK1ve"), activities).Items, 25)
+}
@@ -8,6 +8,9 @@ import (
 // MinimumStringSimilarity is the minimum JaroWinkler distance we accept for search results.
 const MinimumStringSimilarity = 0.89
 
+// exactMatchSimilarity is the similarity of results that were found by their exact ID.
+const exactMatchSimilarity = 10000000
+
 // popularityDamping reduces the factor of popularity in search results.
 const popularityDamping = 0.0009
 
@@ -18,11 +21,45 @@ type Result struct {
 }
 
 // All is a fuzzy search.
+// The term can contain field filters like "type:movie year>2010 genre:Romance",
+// see ParseQuery for the full syntax. Object types that don't support all
+// of the filters used in the term will not return any results.
+// Terms that can't be parsed are treated as free text.
 func All(term string, maxUsers, maxAnime, maxPosts, maxThreads, maxTracks, maxCharacters, maxAMVs, maxCompanies int) ([]*arn.User, []*arn.Anime, []*arn.Post, []*arn.Thread, []*arn.SoundTrack, []*arn.Character, []*arn.AMV, []*arn.Company) {
 	if term == "" {
 		return nil, nil, nil, nil, nil, nil, nil, nil
 	}
 
+	query, err := ParseQuery(term)
+
+	if err != nil {
+		query = NewTextQuery(term)
+	}
+
+	// Object types without filter support only take part in plain text searches.
+	// They search the text of the phrases without the quotes.
+	text := query.Text()
+
+	if query.HasFilters() {
+		maxUsers = 0
+		maxPosts = 0
+		maxCharacters = 0
+		maxAMVs = 0
+		maxCompanies = 0
+	}
+
+	if !query.OnlyUses(animeQueryFields...) {
+		maxAnime = 0
+	}
+
+	if !query.OnlyUses(threadQueryFields...) {
+		maxThreads = 0
+	}
+
+	if !query.OnlyUses(soundTrackQueryFields...) {
+		maxTracks = 0
+	}
+
 	var userResults []*arn.User
 	var animeResults []*arn.Anime
 	var postResults []*arn.Post
@@ -33,21 +70,37 @@ func All(term string, maxUsers, maxAnime, maxPosts, maxThreads, maxTracks, maxCh
 	var companyResults []*arn.Company
 
 	flow.Parallel(func() {
-		userResults = Users(term, maxUsers)
+		if maxUsers > 0 {
+			userResults = Users(text, maxUsers)
+		}
 	}, func() {
-		animeResults = Anime(term, maxAnime)
+		if maxAnime > 0 {
+			animeResults = AnimeByQuery(query, maxAnime)
+		}
 	}, func() {
-		postResults = Posts(term, maxPosts)
+		if maxPosts > 0 {
+			postResults = Posts(text, maxPosts)
+		}
 	}, func() {
-		threadResults = Threads(term, maxThreads)
+		if maxThreads > 0 {
+			threadResults = ThreadsByQuery(query, maxThreads)
+		}
 	}, func() {
-		trackResults = SoundTracks(term, maxTracks)
+		if maxTracks > 0 {
+			trackResults = SoundTracksByQuery(query, maxTracks)
+		}
 	}, func() {
-		characterResults = Characters(term, maxCharacters)
+		if maxCharacters > 0 {
+			characterResults = Characters(text, maxCharacters)
+		}
 	}, func() {
-		amvResults = AMVs(term, maxAMVs)
+		if maxAMVs > 0 {
+			amvResults = AMVs(text, maxAMVs)
+		}
 	}, func() {
-		companyResults = Companies(term, maxCompanies)
+		if maxCompanies > 0 {
+			companyResults = Companies(text, maxCompanies)
+		}
 	})
 
 	return userResults, animeResults, postResults, threadResults, trackResults, characterResults, amvResults, companyResults
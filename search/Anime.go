@@ -2,7 +2,6 @@ package search
 
 import (
 	"sort"
-	"strings"
 
 	"github.com/animenotifier/arn"
 	"github.com/animenotifier/arn/stringutils"
@@ -10,7 +9,45 @@ import (
 
 // Anime searches all anime.
 func Anime(originalTerm string, maxLength int) []*arn.Anime {
-	term := strings.ToLower(stringutils.RemoveSpecialCharacters(originalTerm))
+	return animeResultsToSlice(rankAnime(originalTerm, nil), maxLength)
+}
+
+// AnimeByQuery searches all anime that match the filters and phrases of the query.
+func AnimeByQuery(query *Query, maxLength int) []*arn.Anime {
+	return animeResultsToSlice(rankAnimeByQuery(query), maxLength)
+}
+
+// rankAnimeByQuery returns all anime matching the query, best results first.
+func rankAnimeByQuery(query *Query) []*Result {
+	text := query.Text()
+
+	if !query.HasFilters() && len(query.Phrases) == 0 {
+		return rankAnime(text, nil)
+	}
+
+	if text != "" {
+		return rankAnime(text, query.MatchesAnime)
+	}
+
+	// Without any text we simply rank the anime passing the filters by quality
+	animes := arn.FilterAnime(query.MatchesAnime)
+	arn.SortAnimeByQuality(animes)
+	results := make([]*Result, len(animes))
+
+	for i, anime := range animes {
+		results[i] = &Result{
+			obj:        anime,
+			similarity: anime.Score(),
+		}
+	}
+
+	return results
+}
+
+// rankAnime performs a fuzzy search on anime titles and returns the results sorted by similarity.
+// If a filter is specified, only anime passing the filter are considered.
+func rankAnime(originalTerm string, filter func(*arn.Anime) bool) []*Result {
+	term := normalize(originalTerm)
 
 	var results []*Result
 
@@ -19,7 +56,7 @@ func Anime(originalTerm string, maxLength int) []*arn.Anime {
 			return 0
 		}
 
-		return stringutils.AdvancedStringSimilarity(term, strings.ToLower(stringutils.RemoveSpecialCharacters(text)))
+		return stringutils.AdvancedStringSimilarity(term, normalize(text))
 	}
 
 	add := func(anime *arn.Anime, similarity float64) {
@@ -31,9 +68,27 @@ func Anime(originalTerm string, maxLength int) []*arn.Anime {
 		})
 	}
 
-	for anime := range arn.StreamAnime() {
-		if anime.ID == originalTerm {
-			return []*arn.Anime{anime}
+	// Exact ID match
+	exact, err := arn.GetAnime(originalTerm)
+
+	if err == nil {
+		return []*Result{
+			{
+				obj:        exact,
+				similarity: exactMatchSimilarity,
+			},
+		}
+	}
+
+	for _, obj := range arn.DB.GetMany("Anime", animeIndex.Search(term)) {
+		if obj == nil {
+			continue
+		}
+
+		anime := obj.(*arn.Anime)
+
+		if filter != nil && !filter(anime) {
+			continue
 		}
 
 		// Canonical title
@@ -78,6 +133,24 @@ func Anime(originalTerm string, maxLength int) []*arn.Anime {
 			continue
 		}
 
+		// Hiragana
+		similarity = check(anime.Title.Hiragana)
+
+		if similarity >= MinimumStringSimilarity {
+			add(anime, similarity)
+			continue
+		}
+
+		// Remaining indexed texts, e.g. the word readings of the Japanese title
+		for _, text := range animeIndex.Texts(anime.ID) {
+			similarity := stringutils.AdvancedStringSimilarity(term, text)
+
+			if similarity >= MinimumStringSimilarity {
+				add(anime, similarity)
+				goto nextAnime
+			}
+		}
+
 	nextAnime:
 	}
 
@@ -86,6 +159,11 @@ func Anime(originalTerm string, maxLength int) []*arn.Anime {
 		return results[i].similarity > results[j].similarity
 	})
 
+	return results
+}
+
+// animeResultsToSlice limits the results and returns the anime they refer to.
+func animeResultsToSlice(results []*Result, maxLength int) []*arn.Anime {
 	// Limit
 	if len(results) >= maxLength {
 		results = results[:maxLength]
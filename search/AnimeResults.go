@@ -0,0 +1,85 @@
+package search
+
+import (
+	"github.com/animenotifier/arn"
+)
+
+// AnimeResults is the envelope for anime search results including facet counts.
+type AnimeResults struct {
+	Hits   []*AnimeHit `json:"hits"`
+	Total  int         `json:"total"`
+	Facets AnimeFacets `json:"facets"`
+}
+
+// AnimeHit is a single anime search result.
+type AnimeHit struct {
+	Anime *arn.Anime `json:"anime"`
+	Score float64    `json:"score"`
+}
+
+// AnimeFacets counts the number of hits per facet value.
+// The counts refer to all hits, not only the returned ones.
+type AnimeFacets struct {
+	Types   map[string]int `json:"types"`
+	Genres  map[string]int `json:"genres"`
+	Seasons map[string]int `json:"seasons"`
+	Status  map[string]int `json:"status"`
+	Studios map[string]int `json:"studios"`
+}
+
+// AnimeWithFacets searches all anime matching the query and
+// returns the best hits together with the facet counts of all hits.
+func AnimeWithFacets(query *Query, maxLength int) *AnimeResults {
+	results := rankAnimeByQuery(query)
+
+	envelope := &AnimeResults{
+		Hits:  []*AnimeHit{},
+		Total: len(results),
+		Facets: AnimeFacets{
+			Types:   map[string]int{},
+			Genres:  map[string]int{},
+			Seasons: map[string]int{},
+			Status:  map[string]int{},
+			Studios: map[string]int{},
+		},
+	}
+
+	for index, result := range results {
+		anime := result.obj.(*arn.Anime)
+		envelope.Facets.Add(anime)
+
+		if index < maxLength {
+			envelope.Hits = append(envelope.Hits, &AnimeHit{
+				Anime: anime,
+				Score: result.similarity,
+			})
+		}
+	}
+
+	return envelope
+}
+
+// Add counts the anime in all facets.
+func (facets *AnimeFacets) Add(anime *arn.Anime) {
+	if anime.Type != "" {
+		facets.Types[anime.Type]++
+	}
+
+	if anime.Status != "" {
+		facets.Status[anime.Status]++
+	}
+
+	season := anime.Season()
+
+	if season != "" {
+		facets.Seasons[season]++
+	}
+
+	for _, genre := range anime.Genres {
+		facets.Genres[genre]++
+	}
+
+	for _, studioID := range anime.StudioIDs {
+		facets.Studios[studioID]++
+	}
+}
@@ -0,0 +1,40 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/animenotifier/arn/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnimeFacets(t *testing.T) {
+	facets := &search.AnimeFacets{
+		Types:   map[string]int{},
+		Genres:  map[string]int{},
+		Seasons: map[string]int{},
+		Status:  map[string]int{},
+		Studios: map[string]int{},
+	}
+
+	facets.Add(&arn.Anime{
+		Type:      "tv",
+		Status:    "finished",
+		StartDate: "2011-04-06",
+		Genres:    []string{"Sci-Fi", "Thriller"},
+		StudioIDs: []string{"white-fox"},
+	})
+
+	facets.Add(&arn.Anime{
+		Type:      "movie",
+		Status:    "finished",
+		StartDate: "2013-04-20",
+		Genres:    []string{"Sci-Fi"},
+	})
+
+	assert.Equal(t, map[string]int{"tv": 1, "movie": 1}, facets.Types)
+	assert.Equal(t, map[string]int{"finished": 2}, facets.Status)
+	assert.Equal(t, map[string]int{"Sci-Fi": 2, "Thriller": 1}, facets.Genres)
+	assert.Equal(t, map[string]int{"spring": 2}, facets.Seasons)
+	assert.Equal(t, map[string]int{"white-fox": 1}, facets.Studios)
+}
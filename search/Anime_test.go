@@ -44,6 +44,53 @@ func TestAnimeSearch(t *testing.T) {
 	}
 }
 
+func TestAnimeWithFacets(t *testing.T) {
+	query, err := search.ParseQuery("dragon ball type:tv")
+	assert.NoError(t, err)
+
+	results := search.AnimeWithFacets(query, 5)
+
+	if !assert.NotEmpty(t, results.Hits) {
+		return
+	}
+
+	assert.True(t, len(results.Hits) <= 5)
+	assert.True(t, results.Total >= len(results.Hits))
+	assert.Equal(t, "hbih5KmmR", results.Hits[0].Anime.ID)
+
+	// Hits are ordered by their score
+	for i := 1; i < len(results.Hits); i++ {
+		assert.True(t, results.Hits[i-1].Score >= results.Hits[i].Score)
+	}
+
+	// Facets count all hits, not only the returned ones
+	assert.Equal(t, map[string]int{"tv": results.Total}, results.Facets.Types)
+}
+
+func TestAnimeWithFacetsWithoutText(t *testing.T) {
+	query, err := search.ParseQuery("type:movie genre:Romance")
+	assert.NoError(t, err)
+
+	results := search.AnimeWithFacets(query, 10)
+
+	if !assert.NotEmpty(t, results.Hits) {
+		return
+	}
+
+	// Without text the hits are ranked by their quality score
+	for i, hit := range results.Hits {
+		assert.Equal(t, "movie", hit.Anime.Type)
+		assert.InDelta(t, hit.Anime.Score(), hit.Score, 0.001)
+
+		if i > 0 {
+			assert.True(t, results.Hits[i-1].Score >= hit.Score)
+		}
+	}
+
+	assert.Equal(t, results.Total, results.Facets.Types["movie"])
+	assert.Equal(t, results.Total, results.Facets.Genres["Romance"])
+}
+
 func BenchmarkAnimeSearch(b *testing.B) {
 	b.ReportAllocs()
 
@@ -14,22 +14,31 @@ func Characters(originalTerm string, maxLength int) []*arn.Character {
 		return nil
 	}
 
-	term := strings.ToLower(stringutils.RemoveSpecialCharacters(originalTerm))
-	termHasUnicode := stringutils.ContainsUnicodeLetters(term)
+	term := normalize(originalTerm)
+	termHasUnicode := stringutils.ContainsUnicodeLetters(originalTerm)
 
 	var results []*Result
 
-	for character := range arn.StreamCharacters() {
-		if character.ID == originalTerm {
-			return []*arn.Character{character}
+	// Exact ID match
+	exact, err := arn.GetCharacter(originalTerm)
+
+	if err == nil {
+		return []*arn.Character{exact}
+	}
+
+	for _, obj := range arn.DB.GetMany("Character", characterIndex.Search(term)) {
+		if obj == nil {
+			continue
 		}
 
+		character := obj.(*arn.Character)
+
 		if character.Image.Extension == "" {
 			continue
 		}
 
 		// Canonical
-		text := strings.ToLower(stringutils.RemoveSpecialCharacters(character.Name.Canonical))
+		text := normalize(character.Name.Canonical)
 
 		if text == term {
 			results = append(results, &Result{
@@ -68,7 +77,7 @@ func Characters(originalTerm string, maxLength int) []*arn.Character {
 
 		// Japanese
 		if termHasUnicode {
-			if strings.Contains(character.Name.Japanese, term) {
+			if strings.Contains(normalize(character.Name.Japanese), term) {
 				results = append(results, &Result{
 					obj:        character,
 					similarity: float64(len(character.Likes)),
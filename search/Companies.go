@@ -2,7 +2,6 @@ package search
 
 import (
 	"sort"
-	"strings"
 
 	"github.com/animenotifier/arn"
 	"github.com/animenotifier/arn/stringutils"
@@ -10,20 +9,29 @@ import (
 
 // Companies searches all companies.
 func Companies(originalTerm string, maxLength int) []*arn.Company {
-	term := strings.ToLower(stringutils.RemoveSpecialCharacters(originalTerm))
+	term := normalize(originalTerm)
 
 	var results []*Result
 
-	for company := range arn.StreamCompanies() {
-		if company.ID == originalTerm {
-			return []*arn.Company{company}
+	// Exact ID match
+	exact, err := arn.GetCompany(originalTerm)
+
+	if err == nil {
+		return []*arn.Company{exact}
+	}
+
+	for _, obj := range arn.DB.GetMany("Company", companyIndex.Search(term)) {
+		if obj == nil {
+			continue
 		}
 
+		company := obj.(*arn.Company)
+
 		if company.IsDraft {
 			continue
 		}
 
-		text := strings.ToLower(stringutils.RemoveSpecialCharacters(company.Name.English))
+		text := normalize(company.Name.English)
 		similarity := stringutils.AdvancedStringSimilarity(term, text)
 
 		if similarity >= MinimumStringSimilarity {
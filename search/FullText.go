@@ -0,0 +1,227 @@
+package search
+
+import (
+	"github.com/animenotifier/arn"
+)
+
+// snippetLength is the number of words in the snippets of full-text search results.
+const snippetLength = 30
+
+// maxParentDepth limits how many post parents are followed to find the top-most parent.
+const maxParentDepth = 100
+
+// Full-text indexes for long texts.
+var (
+	postTextIndex   = NewTextIndex(loadPostTexts)
+	threadTextIndex = NewTextIndex(loadThreadTexts)
+)
+
+// TextSearchOptions restricts the results of a full-text search.
+type TextSearchOptions struct {
+	// ParentType only returns posts whose direct parent has this type, e.g. "Thread" or "Group".
+	ParentType string
+
+	// AuthorID only returns objects created by this user.
+	AuthorID string
+
+	// Since and Until restrict the creation date to the range [Since, Until).
+	// Both accept full RFC 3339 dates or prefixes like "2019-01".
+	Since string
+	Until string
+
+	// Viewer is the user who performs the search, nil for anonymous users.
+	// Posts in groups are only visible to members and drafts only to their creator.
+	Viewer *arn.User
+
+	// IncludeLocked also returns locked threads and the posts inside them.
+	IncludeLocked bool
+}
+
+// PostHit is a post found by the full-text search.
+type PostHit struct {
+	Post    *arn.Post `json:"post"`
+	Score   float64   `json:"score"`
+	Snippet string    `json:"snippet"`
+}
+
+// ThreadHit is a thread found by the full-text search.
+type ThreadHit struct {
+	Thread  *arn.Thread `json:"thread"`
+	Score   float64     `json:"score"`
+	Snippet string      `json:"snippet"`
+}
+
+// PostsFullText searches the plain text of all posts and ranks them by relevance.
+// All terms and phrases of the query must appear in the post.
+// Posts don't support any filters, so a query with filters returns no results.
+func PostsFullText(query *Query, options *TextSearchOptions, maxLength int) []*PostHit {
+	if query.HasFilters() {
+		return nil
+	}
+
+	if options == nil {
+		options = &TextSearchOptions{}
+	}
+
+	hits := postTextIndex.Search(query.Terms, query.Phrases)
+	var results []*PostHit
+
+	for _, hit := range hits {
+		if len(results) >= maxLength {
+			break
+		}
+
+		post, err := arn.GetPost(hit.ID)
+
+		if err != nil {
+			continue
+		}
+
+		if options.ParentType != "" && post.ParentType != options.ParentType {
+			continue
+		}
+
+		if !options.matchesCreator(&post.HasCreator) || !options.canSeePost(post) {
+			continue
+		}
+
+		results = append(results, &PostHit{
+			Post:    post,
+			Score:   hit.Score,
+			Snippet: Snippet(postText(post), query.Text(), snippetLength),
+		})
+	}
+
+	return results
+}
+
+// ThreadsFullText searches the title and plain text of all threads and ranks them by relevance.
+// All terms and phrases of the query must appear in the thread and all filters must match.
+func ThreadsFullText(query *Query, options *TextSearchOptions, maxLength int) []*ThreadHit {
+	if !query.OnlyUses(threadQueryFields...) {
+		return nil
+	}
+
+	if options == nil {
+		options = &TextSearchOptions{}
+	}
+
+	hits := threadTextIndex.Search(query.Terms, query.Phrases)
+	var results []*ThreadHit
+
+	for _, hit := range hits {
+		if len(results) >= maxLength {
+			break
+		}
+
+		thread, err := arn.GetThread(hit.ID)
+
+		if err != nil {
+			continue
+		}
+
+		if !query.MatchesThread(thread) || !options.matchesCreator(&thread.HasCreator) {
+			continue
+		}
+
+		if thread.Locked && !options.IncludeLocked {
+			continue
+		}
+
+		results = append(results, &ThreadHit{
+			Thread:  thread,
+			Score:   hit.Score,
+			Snippet: Snippet(plainText(thread.Text), query.Text(), snippetLength),
+		})
+	}
+
+	return results
+}
+
+// matchesCreator tells you whether the author and creation date pass the options.
+func (options *TextSearchOptions) matchesCreator(creator *arn.HasCreator) bool {
+	if options.AuthorID != "" && creator.CreatedBy != options.AuthorID {
+		return false
+	}
+
+	if options.Since != "" && creator.Created < options.Since {
+		return false
+	}
+
+	if options.Until != "" && creator.Created >= options.Until {
+		return false
+	}
+
+	return true
+}
+
+// canSeePost tells you whether the viewer is allowed to see the post.
+// The decision is based on the top-most parent that isn't a post.
+func (options *TextSearchOptions) canSeePost(post *arn.Post) bool {
+	parentType := post.ParentType
+	parentID := post.ParentID
+
+	for depth := 0; depth < maxParentDepth; depth++ {
+		obj, err := arn.DB.Get(parentType, parentID)
+
+		if err != nil {
+			return false
+		}
+
+		parent, isPost := obj.(*arn.Post)
+
+		if isPost {
+			parentType = parent.ParentType
+			parentID = parent.ParentID
+			continue
+		}
+
+		return options.canSeeParent(obj)
+	}
+
+	return false
+}
+
+// canSeeParent tells you whether the viewer is allowed to see the posts in the parent.
+func (options *TextSearchOptions) canSeeParent(parent interface{}) bool {
+	if arn.IsLocked(parent) && !options.IncludeLocked {
+		return false
+	}
+
+	draftable, isDraftable := parent.(arn.Draftable)
+
+	if isDraftable && draftable.GetIsDraft() {
+		creator, hasCreator := parent.(interface{ CreatorID() string })
+		return hasCreator && options.Viewer != nil && creator.CreatorID() == options.Viewer.ID
+	}
+
+	group, isGroup := parent.(*arn.Group)
+
+	if isGroup {
+		return options.Viewer != nil && group.HasMember(options.Viewer.ID)
+	}
+
+	return true
+}
+
+// postText returns the indexed text of a post.
+func postText(post *arn.Post) string {
+	return plainText(post.Text)
+}
+
+// threadText returns the indexed text of a thread.
+func threadText(thread *arn.Thread) string {
+	return thread.Title + "\n" + plainText(thread.Text)
+}
+
+func loadPostTexts(index *TextIndex) {
+	for post := range arn.StreamPosts() {
+		index.add(post.ID, postText(post))
+	}
+}
+
+func loadThreadTexts(index *TextIndex) {
+	for thread := range arn.StreamThreads() {
+		index.add(thread.ID, threadText(thread))
+	}
+}
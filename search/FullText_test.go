@@ -0,0 +1,78 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullTextVisibility(t *testing.T) {
+	member := &arn.User{HasID: arn.HasID{ID: "member"}}
+	stranger := &arn.User{HasID: arn.HasID{ID: "stranger"}}
+
+	group := &arn.Group{
+		Members: []*arn.GroupMember{
+			{UserID: "member"},
+		},
+	}
+
+	draft := &arn.Group{
+		HasCreator: arn.HasCreator{CreatedBy: "member"},
+		HasDraft:   arn.HasDraft{IsDraft: true},
+		Members: []*arn.GroupMember{
+			{UserID: "member"},
+		},
+	}
+
+	thread := &arn.Thread{}
+	locked := &arn.Thread{HasLocked: arn.HasLocked{Locked: true}}
+
+	assert.True(t, (&TextSearchOptions{Viewer: member}).canSeeParent(group))
+	assert.False(t, (&TextSearchOptions{Viewer: stranger}).canSeeParent(group))
+	assert.False(t, (&TextSearchOptions{}).canSeeParent(group))
+	assert.True(t, (&TextSearchOptions{Viewer: member}).canSeeParent(draft))
+	assert.False(t, (&TextSearchOptions{Viewer: stranger}).canSeeParent(draft))
+	assert.True(t, (&TextSearchOptions{}).canSeeParent(thread))
+	assert.False(t, (&TextSearchOptions{}).canSeeParent(locked))
+	assert.True(t, (&TextSearchOptions{IncludeLocked: true}).canSeeParent(locked))
+}
+
+func TestFullTextCreatorFilter(t *testing.T) {
+	creator := &arn.HasCreator{CreatedBy: "author", Created: "2019-05-10T12:00:00Z"}
+
+	assert.True(t, (&TextSearchOptions{}).matchesCreator(creator))
+	assert.True(t, (&TextSearchOptions{AuthorID: "author", Since: "2019-05", Until: "2019-06"}).matchesCreator(creator))
+	assert.False(t, (&TextSearchOptions{AuthorID: "someone"}).matchesCreator(creator))
+	assert.False(t, (&TextSearchOptions{Since: "2019-06"}).matchesCreator(creator))
+	assert.False(t, (&TextSearchOptions{Until: "2019-05-10"}).matchesCreator(creator))
+}
+
+func TestPlainText(t *testing.T) {
+	markdown := "# Episode 12\n\n" +
+		"> Best **episode** of the _season_!\n\n" +
+		"- See [the review](https://example.com/review?id=12) and ![poster](https://example.com/poster.jpg)\n" +
+		"- Source: https://example.com/source\n\n" +
+		"```go\nfmt.Println(\"El Psy Kongroo\")\n```\n\n" +
+		"Tom &amp; Jerry use `snake_case` and <b>HTML</b>.\n\n" +
+		"---"
+
+	text := plainText(markdown)
+	assert.NotContains(t, text, "https")
+	assert.NotContains(t, text, "example")
+	assert.NotContains(t, text, "```")
+	assert.NotContains(t, text, "**")
+	assert.NotContains(t, text, "<b>")
+	assert.Contains(t, text, "Episode 12")
+	assert.Contains(t, text, "Best episode of the season!")
+	assert.Contains(t, text, "See the review and poster")
+	assert.Contains(t, text, "fmt.Println(\"El Psy Kongroo\")")
+	assert.Contains(t, text, "Tom & Jerry use snake_case and HTML.")
+	assert.NotContains(t, text, "---")
+
+	// URLs are not indexed
+	index := NewTextIndex(nil)
+	index.Add("post", postText(&arn.Post{HasText: arn.HasText{Text: markdown}}))
+	assert.Empty(t, index.Search([]string{"example"}, nil))
+	assert.Len(t, index.Search([]string{"review"}, nil), 1)
+}
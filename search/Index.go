@@ -0,0 +1,323 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/animenotifier/arn/stringutils"
+)
+
+// minimumTrigramOverlap is the fraction of query trigrams a document needs to share to become a candidate.
+const minimumTrigramOverlap = 0.25
+
+// maxIndexCandidates limits the number of candidates that will be re-ranked by string similarity.
+const maxIndexCandidates = 2000
+
+// Index is an in-memory trigram index that maps texts to object IDs.
+// It only finds candidates, the final ranking is left to the caller.
+type Index struct {
+	documents  map[string][]string
+	trigrams   map[string]map[string]struct{}
+	vocabulary *Vocabulary
+	loader     func(*Index)
+	loading    bool
+	loaded     bool
+	changes    []func(*Index)
+
+	sync.RWMutex
+}
+
+// NewIndex creates a new index that will be filled by the loader on first use.
+func NewIndex(loader func(*Index)) *Index {
+	return &Index{
+		documents: map[string][]string{},
+		trigrams:  map[string]map[string]struct{}{},
+		loader:    loader,
+	}
+}
+
+// Add adds or replaces the texts for the given ID.
+func (index *Index) Add(id string, texts ...string) {
+	index.Lock()
+	defer index.Unlock()
+
+	index.add(id, texts)
+	index.record(func(loaded *Index) {
+		loaded.add(id, texts)
+	})
+}
+
+// Remove removes the ID from the index.
+func (index *Index) Remove(id string) {
+	index.Lock()
+	defer index.Unlock()
+
+	index.remove(id)
+	index.record(func(loaded *Index) {
+		loaded.remove(id)
+	})
+}
+
+// replace calls the texts function while the index is locked and replaces the texts
+// of the ID with the result. Nothing is changed if the function returns false.
+func (index *Index) replace(id string, texts func() ([]string, bool)) {
+	index.Lock()
+	defer index.Unlock()
+
+	newTexts, ok := texts()
+
+	if !ok {
+		return
+	}
+
+	index.add(id, newTexts)
+	index.record(func(loaded *Index) {
+		loaded.add(id, newTexts)
+	})
+}
+
+// Texts returns the normalized texts stored for the given ID.
+func (index *Index) Texts(id string) []string {
+	index.load()
+
+	index.RLock()
+	defer index.RUnlock()
+
+	return index.documents[id]
+}
+
+// Vocabulary returns all words of the indexed texts.
+// The returned vocabulary is shared and must not be modified.
+func (index *Index) Vocabulary() *Vocabulary {
+	index.load()
+
+	index.Lock()
+	defer index.Unlock()
+
+	if index.vocabulary != nil {
+		return index.vocabulary
+	}
+
+	counts := map[string]int{}
+
+	for _, texts := range index.documents {
+		for _, text := range texts {
+			for _, word := range strings.Fields(text) {
+				counts[word]++
+			}
+		}
+	}
+
+	index.vocabulary = newVocabulary(counts)
+	return index.vocabulary
+}
+
+// Count returns the number of documents in the index.
+func (index *Index) Count() int {
+	index.load()
+
+	index.RLock()
+	defer index.RUnlock()
+
+	return len(index.documents)
+}
+
+// Search returns the IDs of all documents that are similar enough to the given term,
+// ordered by the number of shared trigrams.
+func (index *Index) Search(term string) []string {
+	term = normalize(term)
+
+	if strings.TrimSpace(term) == "" {
+		return nil
+	}
+
+	index.load()
+
+	index.RLock()
+	defer index.RUnlock()
+
+	// Terms that are too short for trigrams are checked against all texts
+	if len([]rune(strings.TrimSpace(term))) < 3 {
+		return index.scan(strings.TrimSpace(term))
+	}
+
+	queryTrigrams := trigrams(term)
+	counts := map[string]int{}
+
+	for trigram := range queryTrigrams {
+		for id := range index.trigrams[trigram] {
+			counts[id]++
+		}
+	}
+
+	required := int(math.Ceil(float64(len(queryTrigrams)) * minimumTrigramOverlap))
+	ids := make([]string, 0, len(counts))
+
+	for id, count := range counts {
+		if count >= required {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if counts[ids[i]] == counts[ids[j]] {
+			return ids[i] < ids[j]
+		}
+
+		return counts[ids[i]] > counts[ids[j]]
+	})
+
+	if len(ids) > maxIndexCandidates {
+		ids = ids[:maxIndexCandidates]
+	}
+
+	return ids
+}
+
+// load fills the index via the loader if that hasn't happened yet.
+// The loader fills a new index that is swapped in when it's complete,
+// so searches during the load don't wait for it and only see the documents added so far.
+// Loaders receive an index that isn't shared yet and therefore use the lock-free add.
+func (index *Index) load() {
+	index.Lock()
+
+	if index.loaded || index.loading {
+		index.Unlock()
+		return
+	}
+
+	if index.loader == nil {
+		index.loaded = true
+		index.Unlock()
+		return
+	}
+
+	index.loading = true
+	index.Unlock()
+
+	loaded := NewIndex(nil)
+	index.loader(loaded)
+
+	index.Lock()
+	defer index.Unlock()
+
+	// Apply the changes made in the meantime
+	for _, change := range index.changes {
+		change(loaded)
+	}
+
+	index.documents = loaded.documents
+	index.trigrams = loaded.trigrams
+	index.vocabulary = nil
+	index.changes = nil
+	index.loading = false
+	index.loaded = true
+}
+
+// record remembers a change made before the load has been completed
+// so that it can be applied to the loaded index.
+// The index must be locked by the caller.
+func (index *Index) record(change func(*Index)) {
+	if index.loaded || index.loader == nil {
+		return
+	}
+
+	index.changes = append(index.changes, change)
+}
+
+// scan returns the IDs of all documents containing the term.
+// The index must be locked by the caller.
+func (index *Index) scan(term string) []string {
+	var ids []string
+
+	for id, texts := range index.documents {
+		for _, text := range texts {
+			if strings.Contains(text, term) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+// add is the lock-free version of Add.
+func (index *Index) add(id string, texts []string) {
+	index.remove(id)
+	index.vocabulary = nil
+
+	normalized := make([]string, 0, len(texts))
+
+	for _, text := range texts {
+		text = normalize(text)
+
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		normalized = append(normalized, text)
+
+		for trigram := range trigrams(text) {
+			ids, exists := index.trigrams[trigram]
+
+			if !exists {
+				ids = map[string]struct{}{}
+				index.trigrams[trigram] = ids
+			}
+
+			ids[id] = struct{}{}
+		}
+	}
+
+	index.documents[id] = normalized
+}
+
+// remove is the lock-free version of Remove.
+func (index *Index) remove(id string) {
+	texts, exists := index.documents[id]
+
+	if !exists {
+		return
+	}
+
+	index.vocabulary = nil
+
+	for _, text := range texts {
+		for trigram := range trigrams(text) {
+			ids := index.trigrams[trigram]
+			delete(ids, id)
+
+			if len(ids) == 0 {
+				delete(index.trigrams, trigram)
+			}
+		}
+	}
+
+	delete(index.documents, id)
+}
+
+// normalize converts the text to the form used for matching.
+// Kana is converted to romaji so that all Japanese scripts match each other.
+func normalize(text string) string {
+	return strings.ToLower(stringutils.RemoveSpecialCharacters(stringutils.NormalizeJapanese(text)))
+}
+
+// trigrams returns the set of trigrams for every word in the text.
+// Words are padded so that word beginnings and endings count as well.
+func trigrams(text string) map[string]struct{} {
+	result := map[string]struct{}{}
+
+	for _, word := range strings.Fields(text) {
+		runes := []rune("  " + word + " ")
+
+		for i := 0; i+3 <= len(runes); i++ {
+			result[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+
+	return result
+}
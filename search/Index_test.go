@@ -0,0 +1,81 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIndex() *search.Index {
+	index := search.NewIndex(nil)
+	index.Add("dragon-ball", "Dragon Ball", "ドラゴンボール")
+	index.Add("dragon-ball-z", "Dragon Ball Z")
+	index.Add("k-on", "K-On!", "けいおん!")
+	index.Add("one-piece", "One Piece")
+	return index
+}
+
+func TestIndexSearch(t *testing.T) {
+	index := newTestIndex()
+
+	assert.Equal(t, 4, index.Count())
+	assert.Contains(t, index.Search("dragn bll"), "dragon-ball")
+	assert.Contains(t, index.Search("one peace"), "one-piece")
+	assert.Contains(t, index.Search("kon"), "k-on")
+	assert.Contains(t, index.Search("ゴンボ"), "dragon-ball")
+	assert.NotContains(t, index.Search("dragon ball"), "one-piece")
+	assert.Empty(t, index.Search(""))
+}
+
+func TestIndexShortTerm(t *testing.T) {
+	index := newTestIndex()
+
+	assert.Equal(t, []string{"k-on"}, index.Search("k"))
+	assert.Equal(t, []string{"k-on"}, index.Search("けい"))
+}
+
+func TestIndexUpdate(t *testing.T) {
+	index := newTestIndex()
+
+	index.Add("one-piece", "Wan Pīsu")
+	assert.NotContains(t, index.Search("one piece"), "one-piece")
+
+	index.Remove("dragon-ball")
+	assert.NotContains(t, index.Search("dragon ball"), "dragon-ball")
+	assert.Contains(t, index.Search("dragon ball"), "dragon-ball-z")
+	assert.Equal(t, 3, index.Count())
+}
+
+func TestIndexLoader(t *testing.T) {
+	calls := 0
+
+	index := search.NewIndex(func(index *search.Index) {
+		calls++
+	})
+
+	index.Search("anything")
+	index.Search("anything else")
+	assert.Equal(t, 1, calls)
+}
+
+func TestIndexChangesWhileLoading(t *testing.T) {
+	var index *search.Index
+
+	index = search.NewIndex(func(loaded *search.Index) {
+		loaded.Add("dragon-ball", "Dragon Ball")
+		loaded.Add("one-piece", "One Piece")
+
+		// Objects saved and deleted while the database is streamed
+		index.Add("k-on", "K-On!")
+		index.Remove("one-piece")
+
+		// Searches during the load don't wait for it
+		assert.Contains(t, index.Search("kon"), "k-on")
+	})
+
+	assert.Contains(t, index.Search("dragon ball"), "dragon-ball")
+	assert.Contains(t, index.Search("kon"), "k-on")
+	assert.Empty(t, index.Search("one piece"))
+	assert.Equal(t, 2, index.Count())
+}
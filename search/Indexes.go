@@ -0,0 +1,163 @@
+package search
+
+import (
+	"github.com/animenotifier/arn"
+)
+
+// Indexes for the searchable object types.
+var (
+	animeIndex     *Index
+	characterIndex = NewIndex(loadCharacters)
+	companyIndex   = NewIndex(loadCompanies)
+	userIndex      = NewIndex(loadUsers)
+)
+
+// Keep the indexes up to date when objects change.
+func init() {
+	// The anime loader refers to the anime index for the readings
+	animeIndex = NewIndex(loadAnime)
+
+	arn.AddDatabaseListener(&indexUpdater{})
+}
+
+// indexUpdater applies database changes to the search indexes.
+type indexUpdater struct{}
+
+// OnSave updates the index entry of the saved object.
+func (updater *indexUpdater) OnSave(typeName string, obj interface{}) {
+	switch typeName {
+	case "Anime":
+		anime := obj.(*arn.Anime)
+		animeIndex.Add(anime.ID, animeTexts(anime)...)
+		queueReadings(animeIndex, anime)
+
+	case "Character":
+		character := obj.(*arn.Character)
+		characterIndex.Add(character.ID, characterTexts(character)...)
+
+	case "Company":
+		company := obj.(*arn.Company)
+		companyIndex.Add(company.ID, companyTexts(company)...)
+
+	case "User":
+		user := obj.(*arn.User)
+		userIndex.Add(user.ID, user.Nick)
+
+	case "Post":
+		post := obj.(*arn.Post)
+		postTextIndex.Add(post.ID, postText(post))
+
+	case "Thread":
+		thread := obj.(*arn.Thread)
+		threadTextIndex.Add(thread.ID, threadText(thread))
+	}
+}
+
+// OnDelete removes the deleted object from the index.
+func (updater *indexUpdater) OnDelete(typeName string, id string) {
+	switch typeName {
+	case "Anime":
+		animeIndex.Remove(id)
+
+	case "Character":
+		characterIndex.Remove(id)
+
+	case "Company":
+		companyIndex.Remove(id)
+
+	case "User":
+		userIndex.Remove(id)
+
+	case "Post":
+		postTextIndex.Remove(id)
+
+	case "Thread":
+		threadTextIndex.Remove(id)
+	}
+}
+
+// animeTexts returns all searchable texts of the anime.
+// The reading of the Japanese title is only included once it has been
+// requested from the tokenizer, see queueReadings.
+func animeTexts(anime *arn.Anime) []string {
+	if anime.Title == nil {
+		return nil
+	}
+
+	texts := animeTitles(anime)
+	reading, _ := cachedReading(anime.Title.Japanese)
+
+	if reading != "" {
+		texts = append(texts, reading)
+	}
+
+	return texts
+}
+
+// animeTitles returns all titles of the anime.
+func animeTitles(anime *arn.Anime) []string {
+	if anime.Title == nil {
+		return nil
+	}
+
+	titles := []string{
+		anime.Title.Canonical,
+		anime.Title.Romaji,
+		anime.Title.English,
+		anime.Title.Japanese,
+		anime.Title.Hiragana,
+	}
+
+	return append(titles, anime.Title.Synonyms...)
+}
+
+// characterTexts returns all searchable names of the character.
+func characterTexts(character *arn.Character) []string {
+	texts := []string{
+		character.Name.Canonical,
+		character.Name.English,
+		character.Name.Japanese,
+	}
+
+	return append(texts, character.Name.Synonyms...)
+}
+
+// companyTexts returns all searchable names of the company.
+func companyTexts(company *arn.Company) []string {
+	texts := []string{
+		company.Name.English,
+		company.Name.Japanese,
+	}
+
+	return append(texts, company.Name.Synonyms...)
+}
+
+func loadAnime(index *Index) {
+	var animes []*arn.Anime
+
+	for anime := range arn.StreamAnime() {
+		index.add(anime.ID, animeTexts(anime))
+		animes = append(animes, anime)
+	}
+
+	// The loaded index replaces the anime index, which receives the readings
+	queueReadings(animeIndex, animes...)
+}
+
+func loadCharacters(index *Index) {
+	for character := range arn.StreamCharacters() {
+		index.add(character.ID, characterTexts(character))
+	}
+}
+
+func loadCompanies(index *Index) {
+	for company := range arn.StreamCompanies() {
+		index.add(company.ID, companyTexts(company))
+	}
+}
+
+func loadUsers(index *Index) {
+	for user := range arn.StreamUsers() {
+		index.add(user.ID, []string{user.Nick})
+	}
+}
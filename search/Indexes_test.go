@@ -0,0 +1,144 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/animenotifier/japanese"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTokenizer splits sentences at "の" and uses a fixed dictionary for readings.
+type fakeTokenizer struct {
+	readings map[string]string
+}
+
+func (tokenizer *fakeTokenizer) Tokenize(sentence string) []*japanese.Token {
+	var tokens []*japanese.Token
+
+	for i, word := range strings.Split(sentence, "の") {
+		if i > 0 {
+			tokens = append(tokens, &japanese.Token{Original: "の", Romaji: "no"})
+		}
+
+		tokens = append(tokens, &japanese.Token{Original: word, Romaji: tokenizer.readings[word]})
+	}
+
+	return tokens
+}
+
+// useTestAnime makes the readings use the given anime instead of the database.
+func useTestAnime(animes ...*arn.Anime) func() {
+	original := readingAnime
+
+	readingAnime = func(id string) (*arn.Anime, error) {
+		for _, anime := range animes {
+			if anime.ID == id {
+				return anime, nil
+			}
+		}
+
+		return nil, errors.New("Anime not found")
+	}
+
+	return func() {
+		readingAnime = original
+	}
+}
+
+func TestJapaneseSearch(t *testing.T) {
+	defer func(original arn.Tokenizer) {
+		arn.JapaneseTokenizer = original
+	}(arn.JapaneseTokenizer)
+
+	arn.JapaneseTokenizer = &fakeTokenizer{
+		readings: map[string]string{
+			"進撃": "shingeki",
+			"巨人": "kyojin",
+		},
+	}
+
+	index := NewIndex(nil)
+
+	index.Add("k-on", animeTexts(&arn.Anime{
+		Title: &arn.AnimeTitle{
+			Canonical: "K-On!",
+			Japanese:  "けいおん！",
+		},
+	})...)
+
+	aot := &arn.Anime{
+		HasID: arn.HasID{ID: "aot"},
+		Title: &arn.AnimeTitle{
+			Canonical: "Attack on Titan",
+			Japanese:  "進撃の巨人",
+		},
+	}
+
+	defer useTestAnime(aot)()
+
+	// Readings are added after the tokenizer has been asked
+	index.Add(aot.ID, animeTexts(aot)...)
+	assert.NotContains(t, index.Texts(aot.ID), "shingeki no kyojin")
+	requestReading(aot.Title.Japanese)
+	applyReading(index, aot.ID, aot.Title.Japanese)
+
+	// Hiragana, katakana, romaji and full-width versions
+	assert.Contains(t, index.Search("けいおん"), "k-on")
+	assert.Contains(t, index.Search("ケイオン"), "k-on")
+	assert.Contains(t, index.Search("keion"), "k-on")
+	assert.Contains(t, index.Search("ｋｅｉｏｎ"), "k-on")
+
+	// Word readings from the tokenizer
+	assert.Contains(t, index.Search("kyojin"), "aot")
+	assert.Contains(t, index.Search("しんげき"), "aot")
+	assert.Contains(t, index.Texts("aot"), "shingeki no kyojin")
+}
+
+func TestJapaneseReadingOutdated(t *testing.T) {
+	defer func(original arn.Tokenizer) {
+		arn.JapaneseTokenizer = original
+	}(arn.JapaneseTokenizer)
+
+	arn.JapaneseTokenizer = &fakeTokenizer{
+		readings: map[string]string{
+			"鋼":    "hagane",
+			"錬金術師": "renkinjutsushi",
+		},
+	}
+
+	index := NewIndex(nil)
+
+	anime := &arn.Anime{
+		HasID: arn.HasID{ID: "fma"},
+		Title: &arn.AnimeTitle{
+			Canonical: "Fullmetal Alchemist",
+			Japanese:  "鋼の錬金術師",
+		},
+	}
+
+	defer useTestAnime(anime)()
+	queued := anime.Title.Japanese
+	index.Add(anime.ID, animeTexts(anime)...)
+	requestReading(queued)
+
+	// Edited while the tokenizer was busy
+	anime.Title = &arn.AnimeTitle{
+		Canonical: "Brotherhood",
+		Japanese:  "鋼の錬金術師 FULLMETAL ALCHEMIST",
+	}
+
+	index.Add(anime.ID, animeTexts(anime)...)
+	applyReading(index, anime.ID, queued)
+	assert.Contains(t, index.Texts(anime.ID), "brotherhood")
+	assert.NotContains(t, index.Texts(anime.ID), "hagane no renkinjutsushi")
+
+	// Deleted while the tokenizer was busy
+	anime.Title.Japanese = queued
+	index.Remove(anime.ID)
+	defer useTestAnime()()
+	applyReading(index, anime.ID, queued)
+	assert.Empty(t, index.Texts(anime.ID))
+}
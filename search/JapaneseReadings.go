@@ -0,0 +1,163 @@
+package search
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/animenotifier/arn"
+)
+
+// readingInterval is the minimum time between two tokenizer requests.
+// Loading the anime index queues a request for every anime with a kanji title.
+const readingInterval = 50 * time.Millisecond
+
+// readingRequest asks for the reading of the Japanese title of an anime
+// to be added to the index.
+type readingRequest struct {
+	index   *Index
+	animeID string
+	text    string
+}
+
+// readingQueue is processed by a single worker so that updates of
+// the same anime are applied in the order they were saved.
+// The queue is unbounded because adding requests must never wait for the tokenizer.
+var readingQueue struct {
+	sync.Mutex
+	value   []*readingRequest
+	pending chan struct{}
+}
+
+// readings caches the readings of Japanese texts.
+// The tokenizer is a network service and must never be called
+// while saving objects or while an index is locked.
+var readings struct {
+	sync.RWMutex
+	value map[string]string
+}
+
+// readingAnime returns the current version of the anime that receives a reading.
+var readingAnime = arn.GetAnime
+
+// Start the reading worker.
+func init() {
+	readings.value = map[string]string{}
+	readingQueue.pending = make(chan struct{}, 1)
+
+	go func() {
+		throttle := time.NewTicker(readingInterval)
+
+		for range readingQueue.pending {
+			readingQueue.Lock()
+			requests := readingQueue.value
+			readingQueue.value = nil
+			readingQueue.Unlock()
+
+			for _, request := range requests {
+				if _, known := cachedReading(request.text); !known {
+					<-throttle.C
+					requestReading(request.text)
+				}
+
+				applyReading(request.index, request.animeID, request.text)
+			}
+		}
+	}()
+}
+
+// queueReadings adds the anime whose reading is not known yet to the reading queue.
+func queueReadings(index *Index, animes ...*arn.Anime) {
+	for _, anime := range animes {
+		if anime.Title == nil || !containsKanji(anime.Title.Japanese) {
+			continue
+		}
+
+		if _, known := cachedReading(anime.Title.Japanese); known {
+			continue
+		}
+
+		readingQueue.Lock()
+		readingQueue.value = append(readingQueue.value, &readingRequest{
+			index:   index,
+			animeID: anime.ID,
+			text:    anime.Title.Japanese,
+		})
+		readingQueue.Unlock()
+	}
+
+	// Wake up the worker unless it has already been notified
+	select {
+	case readingQueue.pending <- struct{}{}:
+	default:
+	}
+}
+
+// requestReading requests the reading of the text from the tokenizer and caches it.
+func requestReading(text string) {
+	reading := japaneseReading(text)
+
+	readings.Lock()
+	readings.value[text] = reading
+	readings.Unlock()
+}
+
+// applyReading updates the index entry of the anime with the cached reading.
+// The anime is loaded again because it might have been edited or deleted
+// while the tokenizer was busy. Anime that have been deleted or whose title
+// has changed in the meantime are left alone, the save that changed them
+// updated the index already.
+func applyReading(index *Index, animeID string, text string) {
+	index.replace(animeID, func() ([]string, bool) {
+		anime, err := readingAnime(animeID)
+
+		if err != nil || anime.Title == nil || anime.Title.Japanese != text {
+			return nil, false
+		}
+
+		return animeTexts(anime), true
+	})
+}
+
+// cachedReading returns the reading of the text if it has already been requested.
+func cachedReading(text string) (string, bool) {
+	readings.RLock()
+	defer readings.RUnlock()
+
+	reading, known := readings.value[text]
+	return reading, known
+}
+
+// japaneseReading splits a Japanese text into words via the tokenizer
+// and returns the romaji readings of the words separated by spaces.
+// Texts without kanji don't need a reading and return an empty string.
+func japaneseReading(text string) string {
+	if !containsKanji(text) {
+		return ""
+	}
+
+	tokens := arn.JapaneseTokenizer.Tokenize(text)
+	words := make([]string, 0, len(tokens))
+
+	for _, token := range tokens {
+		if token.Romaji != "" {
+			words = append(words, token.Romaji)
+		} else {
+			words = append(words, token.Original)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// containsKanji tells you whether the text contains any kanji.
+func containsKanji(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Markdown syntax that is removed by plainText, in the order it's applied.
+var markdownReplacements = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// Code fences, the code itself is kept
+	{regexp.MustCompile("(?m)^[ \\t]*(```|~~~).*$"), ""},
+
+	// Images and links keep their text, reference definitions are removed
+	{regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`), "$1"},
+	{regexp.MustCompile(`(?m)^[ \t]*\[[^\]]+\]:[ \t]*\S+.*$`), ""},
+
+	// HTML tags and URLs that are not part of a link text
+	{regexp.MustCompile(`<[^<>\n]*>`), ""},
+	{regexp.MustCompile(`(https?|ftp)://[^\s<>]+`), ""},
+
+	// Headings, quotes, list markers and horizontal rules
+	{regexp.MustCompile(`(?m)^[ \t]*#{1,6}[ \t]+`), ""},
+	{regexp.MustCompile(`(?m)^[ \t]*(>[ \t]*)+`), ""},
+	{regexp.MustCompile(`(?m)^[ \t]*([-*+]|\d+\.)[ \t]+`), ""},
+	{regexp.MustCompile(`(?m)^[ \t]*([-*_][ \t]*){3,}$`), ""},
+
+	// Emphasis, strikethrough and inline code
+	{regexp.MustCompile("[*~`]+"), ""},
+	{regexp.MustCompile(`(^|[^\pL\pN_])_+|_+([^\pL\pN_]|$)`), "$1$2"},
+}
+
+// plainText removes the Markdown syntax from the text so that link URLs
+// and formatting characters don't end up in the index and in snippets.
+func plainText(markdown string) string {
+	text := markdown
+
+	for _, markdownReplacement := range markdownReplacements {
+		text = markdownReplacement.pattern.ReplaceAllString(text, markdownReplacement.replacement)
+	}
+
+	return strings.TrimSpace(html.UnescapeString(text))
+}
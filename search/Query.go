@@ -0,0 +1,295 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/animenotifier/arn"
+)
+
+// Query operators
+const (
+	OperatorEqual        = ":"
+	OperatorGreater      = ">"
+	OperatorGreaterEqual = ">="
+	OperatorLess         = "<"
+	OperatorLessEqual    = "<="
+)
+
+// queryFields maps every known filter field to whether it allows numeric comparisons.
+var queryFields = map[string]bool{
+	"type":   false,
+	"year":   true,
+	"genre":  false,
+	"status": false,
+	"studio": false,
+	"rating": true,
+	"tag":    false,
+	"anime":  false,
+}
+
+// Query is a parsed search query.
+// Example: type:movie year>=2010 genre:Romance "kimi no" shinkai
+type Query struct {
+	Terms   []string       `json:"terms"`
+	Phrases []string       `json:"phrases"`
+	Filters []*QueryFilter `json:"filters"`
+}
+
+// QueryFilter restricts the results to objects whose field matches the value.
+type QueryFilter struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+	Position int    `json:"position"`
+}
+
+// QueryError is returned when a query can not be parsed.
+// The position is the index of the character that caused the error.
+type QueryError struct {
+	Position int
+	Message  string
+}
+
+// Error implements the error interface.
+func (err *QueryError) Error() string {
+	return fmt.Sprintf("%s at position %d", err.Message, err.Position)
+}
+
+// NewTextQuery creates a query that only consists of free text.
+func NewTextQuery(term string) *Query {
+	return &Query{
+		Terms: strings.Fields(term),
+	}
+}
+
+// Text returns the free text part of the query including phrases.
+func (query *Query) Text() string {
+	parts := make([]string, 0, len(query.Terms)+len(query.Phrases))
+	parts = append(parts, query.Terms...)
+	parts = append(parts, query.Phrases...)
+	return strings.Join(parts, " ")
+}
+
+// HasFilters tells you whether the query contains field filters.
+func (query *Query) HasFilters() bool {
+	return len(query.Filters) > 0
+}
+
+// OnlyUses tells you whether all filters in the query refer to one of the given fields.
+func (query *Query) OnlyUses(fields ...string) bool {
+	for _, filter := range query.Filters {
+		if !arn.Contains(fields, filter.Field) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseQuery parses the query syntax used by the search.
+func ParseQuery(input string) (*Query, error) {
+	query := &Query{}
+	runes := []rune(input)
+	pos := 0
+
+	for pos < len(runes) {
+		if unicode.IsSpace(runes[pos]) {
+			pos++
+			continue
+		}
+
+		// Phrase
+		if runes[pos] == '"' {
+			phrase, next, err := readQuoted(runes, pos)
+
+			if err != nil {
+				return nil, err
+			}
+
+			phrase = strings.TrimSpace(phrase)
+
+			if phrase != "" {
+				query.Phrases = append(query.Phrases, phrase)
+			}
+
+			pos = next
+			continue
+		}
+
+		// Field name
+		fieldEnd := pos
+
+		for fieldEnd < len(runes) && unicode.IsLetter(runes[fieldEnd]) {
+			fieldEnd++
+		}
+
+		field := strings.ToLower(string(runes[pos:fieldEnd]))
+		operator := readOperator(runes, fieldEnd)
+		_, isField := queryFields[field]
+
+		// Anything that isn't a known filter is a free text term
+		if operator == "" || !isField {
+			end := pos
+
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+
+			query.Terms = append(query.Terms, string(runes[pos:end]))
+			pos = end
+			continue
+		}
+
+		filter, next, err := readFilter(runes, pos, field, fieldEnd, operator)
+
+		if err != nil {
+			return nil, err
+		}
+
+		query.Filters = append(query.Filters, filter)
+		pos = next
+	}
+
+	return query, nil
+}
+
+// readFilter reads the value of a filter and validates it.
+func readFilter(runes []rune, start int, field string, operatorStart int, operator string) (*QueryFilter, int, error) {
+	valueStart := operatorStart + len(operator)
+	value := ""
+	next := valueStart
+
+	if valueStart < len(runes) && runes[valueStart] == '"' {
+		quoted, end, err := readQuoted(runes, valueStart)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		value = quoted
+		next = end
+	} else {
+		for next < len(runes) && !unicode.IsSpace(runes[next]) && runes[next] != '"' {
+			next++
+		}
+
+		value = string(runes[valueStart:next])
+	}
+
+	if operator == "=" {
+		operator = OperatorEqual
+	}
+
+	if operator != OperatorEqual && !queryFields[field] {
+		return nil, 0, &QueryError{
+			Position: operatorStart,
+			Message:  fmt.Sprintf("Operator '%s' is not supported for '%s'", operator, field),
+		}
+	}
+
+	if value == "" {
+		return nil, 0, &QueryError{
+			Position: valueStart,
+			Message:  fmt.Sprintf("Missing value for '%s'", field),
+		}
+	}
+
+	message := validateFilterValue(field, value)
+
+	if message != "" {
+		return nil, 0, &QueryError{
+			Position: valueStart,
+			Message:  message,
+		}
+	}
+
+	filter := &QueryFilter{
+		Field:    field,
+		Operator: operator,
+		Value:    value,
+		Position: start,
+	}
+
+	return filter, next, nil
+}
+
+// validateFilterValue returns an error message if the value is not valid for the field.
+func validateFilterValue(field string, value string) string {
+	switch field {
+	case "year":
+		_, err := strconv.Atoi(value)
+
+		if err != nil {
+			return fmt.Sprintf("Invalid year '%s'", value)
+		}
+
+	case "rating":
+		rating, err := strconv.ParseFloat(value, 64)
+
+		if err != nil || rating < 0 || rating > arn.MaxRating {
+			return fmt.Sprintf("Invalid rating '%s'", value)
+		}
+
+	case "type":
+		if !isOption("anime-types", value) {
+			return fmt.Sprintf("Unknown anime type '%s'", value)
+		}
+
+	case "status":
+		if !isOption("anime-status", value) {
+			return fmt.Sprintf("Unknown anime status '%s'", value)
+		}
+	}
+
+	return ""
+}
+
+// readOperator returns the operator starting at the given position.
+func readOperator(runes []rune, pos int) string {
+	if pos >= len(runes) {
+		return ""
+	}
+
+	switch runes[pos] {
+	case ':', '=':
+		return string(runes[pos])
+
+	case '>', '<':
+		if pos+1 < len(runes) && runes[pos+1] == '=' {
+			return string(runes[pos : pos+2])
+		}
+
+		return string(runes[pos])
+	}
+
+	return ""
+}
+
+// readQuoted reads a quoted string starting at the opening quote
+// and returns the contents and the position after the closing quote.
+func readQuoted(runes []rune, start int) (string, int, error) {
+	for end := start + 1; end < len(runes); end++ {
+		if runes[end] == '"' {
+			return string(runes[start+1 : end]), end + 1, nil
+		}
+	}
+
+	return "", 0, &QueryError{
+		Position: start,
+		Message:  "Unterminated quote",
+	}
+}
+
+// isOption tells you whether the value is part of the given data list.
+func isOption(dataList string, value string) bool {
+	for _, option := range arn.DataLists[dataList] {
+		if strings.EqualFold(option.Value, value) {
+			return true
+		}
+	}
+
+	return false
+}
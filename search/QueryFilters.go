@@ -0,0 +1,156 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/animenotifier/arn"
+)
+
+// Fields that can be used to filter the different object types.
+var (
+	animeQueryFields      = []string{"type", "year", "genre", "status", "studio", "rating"}
+	soundTrackQueryFields = []string{"tag", "anime"}
+	threadQueryFields     = []string{"tag"}
+)
+
+// MatchesAnime tells you whether the anime passes all filters and contains all phrases.
+func (query *Query) MatchesAnime(anime *arn.Anime) bool {
+	for _, filter := range query.Filters {
+		switch filter.Field {
+		case "type":
+			if !strings.EqualFold(anime.Type, filter.Value) {
+				return false
+			}
+
+		case "status":
+			if !strings.EqualFold(anime.Status, filter.Value) {
+				return false
+			}
+
+		case "genre":
+			if !containsFold(anime.Genres, filter.Value) {
+				return false
+			}
+
+		case "studio":
+			if !arn.Contains(anime.StudioIDs, filter.Value) {
+				return false
+			}
+
+		case "year":
+			if anime.StartDate == "" || !filter.compare(float64(anime.StartDateTime().Year())) {
+				return false
+			}
+
+		case "rating":
+			if anime.Rating == nil || !filter.compare(anime.Rating.Overall) {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+
+	if anime.Title == nil {
+		return len(query.Phrases) == 0
+	}
+
+	return query.containsPhrases(animeTitles(anime))
+}
+
+// MatchesSoundTrack tells you whether the soundtrack passes all filters and contains all phrases.
+func (query *Query) MatchesSoundTrack(track *arn.SoundTrack) bool {
+	for _, filter := range query.Filters {
+		switch filter.Field {
+		case "tag":
+			if !containsFold(track.Tags, filter.Value) {
+				return false
+			}
+
+		case "anime":
+			if !track.HasTag("anime:" + filter.Value) {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+
+	return query.containsPhrases([]string{track.Title.Canonical, track.Title.Native})
+}
+
+// MatchesThread tells you whether the thread passes all filters and contains all phrases.
+func (query *Query) MatchesThread(thread *arn.Thread) bool {
+	for _, filter := range query.Filters {
+		switch filter.Field {
+		case "tag":
+			if !containsFold(thread.Tags, filter.Value) {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+
+	return query.containsPhrases([]string{thread.Title, thread.Text})
+}
+
+// containsPhrases tells you whether every phrase appears in at least one of the texts.
+func (query *Query) containsPhrases(texts []string) bool {
+	for _, phrase := range query.Phrases {
+		phrase = strings.TrimSpace(normalize(phrase))
+		found := false
+
+		for _, text := range texts {
+			if strings.Contains(normalize(text), phrase) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compare applies the numeric operator of the filter to the given value.
+func (filter *QueryFilter) compare(value float64) bool {
+	expected, err := strconv.ParseFloat(filter.Value, 64)
+
+	if err != nil {
+		return false
+	}
+
+	switch filter.Operator {
+	case OperatorEqual:
+		return value == expected
+	case OperatorGreater:
+		return value > expected
+	case OperatorGreaterEqual:
+		return value >= expected
+	case OperatorLess:
+		return value < expected
+	case OperatorLessEqual:
+		return value <= expected
+	default:
+		return false
+	}
+}
+
+// containsFold is a case-insensitive version of arn.Contains.
+func containsFold(collection []string, value string) bool {
+	for _, element := range collection {
+		if strings.EqualFold(element, value) {
+			return true
+		}
+	}
+
+	return false
+}
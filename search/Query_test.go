@@ -0,0 +1,159 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn"
+	"github.com/animenotifier/arn/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	query, err := search.ParseQuery(`type:movie year:2019 genre:Romance status:finished studio:abc rating>7 "exact phrase" kimi`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kimi"}, query.Terms)
+	assert.Equal(t, []string{"exact phrase"}, query.Phrases)
+	assert.Len(t, query.Filters, 6)
+
+	expected := []search.QueryFilter{
+		{Field: "type", Operator: ":", Value: "movie", Position: 0},
+		{Field: "year", Operator: ":", Value: "2019", Position: 11},
+		{Field: "genre", Operator: ":", Value: "Romance", Position: 21},
+		{Field: "status", Operator: ":", Value: "finished", Position: 35},
+		{Field: "studio", Operator: ":", Value: "abc", Position: 51},
+		{Field: "rating", Operator: ">", Value: "7", Position: 62},
+	}
+
+	for i, filter := range query.Filters {
+		assert.Equal(t, expected[i], *filter)
+	}
+
+	assert.Equal(t, "kimi exact phrase", query.Text())
+}
+
+func TestParseQueryOperators(t *testing.T) {
+	tests := map[string]string{
+		"year>2010":  ">",
+		"year>=2010": ">=",
+		"year<2010":  "<",
+		"year<=2010": "<=",
+		"year=2010":  ":",
+		"year:2010":  ":",
+	}
+
+	for input, operator := range tests {
+		query, err := search.ParseQuery(input)
+		assert.NoError(t, err, input)
+		assert.Len(t, query.Filters, 1, input)
+		assert.Equal(t, operator, query.Filters[0].Operator, input)
+		assert.Equal(t, "2010", query.Filters[0].Value, input)
+	}
+}
+
+func TestParseQueryFreeText(t *testing.T) {
+	query, err := search.ParseQuery(`re:zero  steins;gate genre:"Slice of Life"`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"re:zero", "steins;gate"}, query.Terms)
+	assert.Len(t, query.Filters, 1)
+	assert.Equal(t, "Slice of Life", query.Filters[0].Value)
+	assert.False(t, query.OnlyUses("tag"))
+	assert.True(t, query.OnlyUses("genre", "tag"))
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+	}{
+		{`naruto "shippuden`, 7},
+		{`year:`, 5},
+		{`year:twenty`, 5},
+		{`rating>11`, 7},
+		{`rating:abc`, 7},
+		{`genre>Romance`, 5},
+		{`type:moovie`, 5},
+		{`status:airing`, 7},
+		{`anime:"abc`, 6},
+		{`ドラゴン year:x`, 10},
+	}
+
+	for _, test := range tests {
+		query, err := search.ParseQuery(test.input)
+		assert.Nil(t, query, test.input)
+		assert.Error(t, err, test.input)
+
+		queryErr, ok := err.(*search.QueryError)
+		assert.True(t, ok, test.input)
+		assert.Equal(t, test.position, queryErr.Position, test.input)
+	}
+}
+
+func TestQueryMatchesAnime(t *testing.T) {
+	anime := &arn.Anime{
+		Type:      "movie",
+		Status:    "finished",
+		Genres:    []string{"Romance", "Drama"},
+		StartDate: "2016-08-26",
+		StudioIDs: []string{"comix-wave"},
+		Title: &arn.AnimeTitle{
+			Canonical: "Kimi no Na wa.",
+			English:   "Your Name.",
+		},
+		Rating: &arn.AnimeRating{
+			AnimeListItemRating: arn.AnimeListItemRating{
+				Overall: 8.5,
+			},
+		},
+	}
+
+	matches := map[string]bool{
+		`type:movie`:                         true,
+		`type:tv`:                            false,
+		`genre:romance status:finished`:      true,
+		`genre:Comedy`:                       false,
+		`year:2016`:                          true,
+		`year>2016`:                          false,
+		`year>=2016 year<2017`:               true,
+		`studio:comix-wave`:                  true,
+		`studio:kyoani`:                      false,
+		`rating>8`:                           true,
+		`rating<=8`:                          false,
+		`"your name"`:                        true,
+		`"their name"`:                       false,
+		`tag:opening`:                        false,
+		`type:movie "kimi no" rating>=8.5 x`: true,
+	}
+
+	for input, expected := range matches {
+		query, err := search.ParseQuery(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, query.MatchesAnime(anime), input)
+	}
+}
+
+func TestQueryMatchesSoundTrackAndThread(t *testing.T) {
+	track := &arn.SoundTrack{
+		Title: arn.SoundTrackTitle{
+			Canonical: "Zenzenzense",
+		},
+		Tags: []string{"anime:abc", "opening", "vocal"},
+	}
+
+	thread := &arn.Thread{
+		Title: "Patch notes",
+		Tags:  []string{"update"},
+	}
+
+	query, _ := search.ParseQuery(`tag:opening anime:abc`)
+	assert.True(t, query.MatchesSoundTrack(track))
+	assert.False(t, query.MatchesThread(thread))
+
+	query, _ = search.ParseQuery(`anime:xyz`)
+	assert.False(t, query.MatchesSoundTrack(track))
+
+	query, _ = search.ParseQuery(`tag:update "patch"`)
+	assert.True(t, query.MatchesThread(thread))
+	assert.False(t, query.MatchesSoundTrack(track))
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// snippetEllipsis marks text that has been cut off in a snippet.
+const snippetEllipsis = "…"
+
+// Snippet returns an excerpt of at most maxWords words from the text around the best matching
+// region. The excerpt is HTML-escaped and every word of the query is wrapped in <mark> tags.
+// If the query doesn't appear in the text, the beginning of the text is returned.
+func Snippet(text string, query string, maxWords int) string {
+	tokens := tokenize(text)
+
+	if len(tokens) == 0 || maxWords <= 0 {
+		return ""
+	}
+
+	queryWords := map[string]bool{}
+
+	for _, word := range tokenWords(query) {
+		queryWords[word] = true
+	}
+
+	matches := make([]bool, len(tokens))
+
+	for i, token := range tokens {
+		matches[i] = queryWords[token.Word]
+	}
+
+	// Find the window with the highest number of matches,
+	// leaving a bit of context before the first match.
+	bestStart := 0
+	bestCount := countMatches(matches, 0, maxWords)
+
+	for i, isMatch := range matches {
+		if !isMatch {
+			continue
+		}
+
+		start := i - maxWords/4
+
+		if start < 0 {
+			start = 0
+		}
+
+		count := countMatches(matches, start, start+maxWords)
+
+		if count > bestCount {
+			bestStart = start
+			bestCount = count
+		}
+	}
+
+	end := bestStart + maxWords
+
+	if end > len(tokens) {
+		end = len(tokens)
+	}
+
+	snippet := strings.Builder{}
+
+	if bestStart > 0 {
+		snippet.WriteString(snippetEllipsis)
+	}
+
+	position := tokens[bestStart].Start
+
+	for i := bestStart; i < end; i++ {
+		if !matches[i] {
+			continue
+		}
+
+		token := tokens[i]
+		snippet.WriteString(html.EscapeString(text[position:token.Start]))
+		snippet.WriteString("<mark>")
+		snippet.WriteString(html.EscapeString(text[token.Start:token.End]))
+		snippet.WriteString("</mark>")
+		position = token.End
+	}
+
+	snippet.WriteString(html.EscapeString(text[position:tokens[end-1].End]))
+
+	if end < len(tokens) {
+		snippet.WriteString(snippetEllipsis)
+	}
+
+	return snippet.String()
+}
+
+// countMatches returns the number of matching tokens in the given range.
+func countMatches(matches []bool, start int, end int) int {
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	count := 0
+
+	for _, isMatch := range matches[start:end] {
+		if isMatch {
+			count++
+		}
+	}
+
+	return count
+}
@@ -10,6 +10,38 @@ import (
 
 // SoundTracks searches all soundtracks.
 func SoundTracks(originalTerm string, maxLength int) []*arn.SoundTrack {
+	return searchSoundTracks(originalTerm, nil, maxLength)
+}
+
+// SoundTracksByQuery searches all soundtracks that match the filters and phrases of the query.
+func SoundTracksByQuery(query *Query, maxLength int) []*arn.SoundTrack {
+	text := query.Text()
+
+	if !query.HasFilters() && len(query.Phrases) == 0 {
+		return SoundTracks(text, maxLength)
+	}
+
+	// Without any text we simply return the most popular tracks passing the filters
+	if text == "" {
+		results := arn.FilterSoundTracks(func(track *arn.SoundTrack) bool {
+			return !track.IsDraft && query.MatchesSoundTrack(track)
+		})
+
+		arn.SortSoundTracksPopularFirst(results)
+
+		if len(results) >= maxLength {
+			results = results[:maxLength]
+		}
+
+		return results
+	}
+
+	return searchSoundTracks(text, query.MatchesSoundTrack, maxLength)
+}
+
+// searchSoundTracks performs a fuzzy search on soundtrack titles.
+// If a filter is specified, only tracks passing the filter are considered.
+func searchSoundTracks(originalTerm string, filter func(*arn.SoundTrack) bool, maxLength int) []*arn.SoundTrack {
 	term := strings.ToLower(stringutils.RemoveSpecialCharacters(originalTerm))
 
 	var results []*Result
@@ -23,6 +55,10 @@ func SoundTracks(originalTerm string, maxLength int) []*arn.SoundTrack {
 			continue
 		}
 
+		if filter != nil && !filter(track) {
+			continue
+		}
+
 		text := strings.ToLower(track.Title.Canonical)
 		similarity := stringutils.AdvancedStringSimilarity(term, text)
 
@@ -0,0 +1,194 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/animenotifier/arn"
+	"github.com/animenotifier/arn/stringutils"
+)
+
+// Suggestion is a title or nick that is similar to the search term
+// but not similar enough to pass the MinimumStringSimilarity threshold.
+type Suggestion struct {
+	ID         string  `json:"id"`
+	Text       string  `json:"text"`
+	Term       string  `json:"term"`
+	Distance   int     `json:"distance"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Explanation describes how much the suggestion differs from the search term.
+func (suggestion *Suggestion) Explanation() string {
+	return fmt.Sprintf("\"%s\" differs from \"%s\" by %s", suggestion.Text, suggestion.Term, stringutils.Plural(suggestion.Distance, "edit"))
+}
+
+// AnimeSuggestions returns the anime titles closest to a term that didn't yield any results.
+// The term is spell-checked against the vocabulary of all anime titles first.
+func AnimeSuggestions(originalTerm string, maxLength int) []*Suggestion {
+	term := normalize(originalTerm)
+	ids := animeIndex.Search(term)
+	corrected := CorrectSpelling(originalTerm)
+
+	if corrected != strings.Join(strings.Fields(term), " ") {
+		ids = append(ids, animeIndex.Search(corrected)...)
+	}
+
+	var suggestions []*Suggestion
+	seen := map[string]bool{}
+
+	for _, obj := range arn.DB.GetMany("Anime", ids) {
+		if obj == nil {
+			continue
+		}
+
+		anime := obj.(*arn.Anime)
+
+		if seen[anime.ID] {
+			continue
+		}
+
+		seen[anime.ID] = true
+		suggestion := closestSuggestion(anime.ID, originalTerm, animeTitles(anime))
+
+		if suggestion != nil {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return sortSuggestions(suggestions, maxLength)
+}
+
+// UserSuggestions returns the nicks closest to a term that didn't yield any results.
+func UserSuggestions(originalTerm string, maxLength int) []*Suggestion {
+	var suggestions []*Suggestion
+
+	for _, obj := range arn.DB.GetMany("User", userIndex.Search(originalTerm)) {
+		if obj == nil {
+			continue
+		}
+
+		user := obj.(*arn.User)
+		suggestion := closestSuggestion(user.ID, originalTerm, []string{user.Nick})
+
+		if suggestion != nil {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return sortSuggestions(suggestions, maxLength)
+}
+
+// CorrectSpelling replaces every word of the term that doesn't appear in any anime title
+// with the closest word from the titles. The returned term is normalized.
+func CorrectSpelling(originalTerm string) string {
+	words := strings.Fields(normalize(originalTerm))
+	vocabulary := animeIndex.Vocabulary()
+
+	for i, word := range words {
+		words[i] = correctWord(word, vocabulary)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// correctWord returns the vocabulary word with the smallest edit distance.
+// Frequent words are preferred when the distance is equal.
+// Only words sharing enough trigrams with the word are compared.
+func correctWord(word string, vocabulary *Vocabulary) string {
+	length := len([]rune(word))
+
+	// Very short words and known words are not corrected
+	if length <= 2 || vocabulary.Counts[word] > 0 {
+		return word
+	}
+
+	maxDistance := 2
+
+	if length <= 4 {
+		maxDistance = 1
+	}
+
+	best := word
+	bestDistance := maxDistance + 1
+	bestCount := 0
+
+	for _, candidate := range vocabulary.Candidates(word) {
+		count := vocabulary.Counts[candidate]
+		lengthDifference := len([]rune(candidate)) - length
+
+		if lengthDifference > maxDistance || -lengthDifference > maxDistance {
+			continue
+		}
+
+		distance := stringutils.EditDistance(word, candidate)
+
+		if distance < bestDistance || (distance == bestDistance && (count > bestCount || (count == bestCount && candidate < best))) {
+			best = candidate
+			bestDistance = distance
+			bestCount = count
+		}
+	}
+
+	return best
+}
+
+// closestSuggestion returns the suggestion for the text with the smallest edit distance to the term.
+// Texts that would have been found by the regular search are ignored.
+func closestSuggestion(id string, originalTerm string, texts []string) *Suggestion {
+	term := normalize(originalTerm)
+	var best *Suggestion
+
+	for _, text := range texts {
+		normalized := normalize(text)
+
+		if strings.TrimSpace(normalized) == "" {
+			continue
+		}
+
+		similarity := stringutils.AdvancedStringSimilarity(term, normalized)
+
+		if similarity >= MinimumStringSimilarity {
+			return nil
+		}
+
+		distance := stringutils.EditDistance(term, normalized)
+
+		if best == nil || distance < best.Distance {
+			best = &Suggestion{
+				ID:         id,
+				Text:       text,
+				Term:       originalTerm,
+				Distance:   distance,
+				Similarity: similarity,
+			}
+		}
+	}
+
+	return best
+}
+
+// sortSuggestions puts the closest suggestions first and limits the result.
+func sortSuggestions(suggestions []*Suggestion, maxLength int) []*Suggestion {
+	sort.Slice(suggestions, func(i, j int) bool {
+		a := suggestions[i]
+		b := suggestions[j]
+
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+
+		return a.Text < b.Text
+	})
+
+	if len(suggestions) >= maxLength {
+		suggestions = suggestions[:maxLength]
+	}
+
+	return suggestions
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectWord(t *testing.T) {
+	index := NewIndex(nil)
+	index.Add("dragon-ball", "Dragon Ball")
+	index.Add("dragon-ball-z", "Dragon Ball Z")
+	index.Add("one-piece", "One Piece")
+	index.Add("bell", "Bell")
+	vocabulary := index.Vocabulary()
+
+	assert.Equal(t, "dragon", correctWord("dragn", vocabulary))
+	assert.Equal(t, "piece", correctWord("peice", vocabulary))
+	assert.Equal(t, "ball", correctWord("bal", vocabulary))
+	assert.Equal(t, "one", correctWord("one", vocabulary))
+	assert.Equal(t, "zz", correctWord("zz", vocabulary))
+	assert.Equal(t, "xyzxyz", correctWord("xyzxyz", vocabulary))
+}
+
+func TestVocabularyCandidates(t *testing.T) {
+	index := NewIndex(nil)
+	index.Add("dragon-ball", "Dragon Ball")
+	index.Add("one-piece", "One Piece")
+	vocabulary := index.Vocabulary()
+
+	assert.Equal(t, 1, vocabulary.Counts["dragon"])
+	assert.Equal(t, []string{"dragon"}, vocabulary.Candidates("dragn"))
+	assert.Equal(t, []string{"piece"}, vocabulary.Candidates("peice"))
+	assert.Empty(t, vocabulary.Candidates("xyz"))
+}
+
+func TestSuggestionExplanation(t *testing.T) {
+	suggestion := closestSuggestion("dragon-ball", "drgon bal", []string{"One Piece", "Dragon Ball"})
+
+	assert.NotNil(t, suggestion)
+	assert.Equal(t, "Dragon Ball", suggestion.Text)
+	assert.Equal(t, 2, suggestion.Distance)
+	assert.Equal(t, `"Dragon Ball" differs from "drgon bal" by 2 edits`, suggestion.Explanation())
+	assert.Nil(t, closestSuggestion("dragon-ball", "dragon ball", []string{"Dragon Ball"}))
+}
@@ -0,0 +1,393 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/animenotifier/arn/stringutils"
+)
+
+// BM25 parameters for full-text ranking.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// TextIndex is an in-memory inverted index for long texts like posts.
+// Documents are ranked with BM25 and phrases are matched via word positions.
+type TextIndex struct {
+	postings    map[string]map[string][]int
+	documents   map[string][]string
+	lengths     map[string]int
+	totalLength int
+	loader      func(*TextIndex)
+	loading     bool
+	loaded      bool
+	changes     []func(*TextIndex)
+
+	sync.RWMutex
+}
+
+// TextHit is a document found in the text index.
+type TextHit struct {
+	ID    string
+	Score float64
+}
+
+// textToken is a single word in a text including its byte offsets in the original text.
+type textToken struct {
+	Word  string
+	Start int
+	End   int
+}
+
+// NewTextIndex creates a new text index that will be filled by the loader on first use.
+func NewTextIndex(loader func(*TextIndex)) *TextIndex {
+	return &TextIndex{
+		postings:  map[string]map[string][]int{},
+		documents: map[string][]string{},
+		lengths:   map[string]int{},
+		loader:    loader,
+	}
+}
+
+// Add adds or replaces the text for the given ID.
+func (index *TextIndex) Add(id string, text string) {
+	index.Lock()
+	defer index.Unlock()
+
+	index.add(id, text)
+	index.record(func(loaded *TextIndex) {
+		loaded.add(id, text)
+	})
+}
+
+// Remove removes the ID from the index.
+func (index *TextIndex) Remove(id string) {
+	index.Lock()
+	defer index.Unlock()
+
+	index.remove(id)
+	index.record(func(loaded *TextIndex) {
+		loaded.remove(id)
+	})
+}
+
+// Count returns the number of documents in the index.
+func (index *TextIndex) Count() int {
+	index.load()
+
+	index.RLock()
+	defer index.RUnlock()
+
+	return len(index.lengths)
+}
+
+// Search returns all documents containing every term and every phrase,
+// ordered by their BM25 score.
+func (index *TextIndex) Search(terms []string, phrases []string) []*TextHit {
+	var words []string
+	var phraseWords [][]string
+
+	for _, term := range terms {
+		words = append(words, tokenWords(term)...)
+	}
+
+	for _, phrase := range phrases {
+		phraseTokens := tokenWords(phrase)
+
+		if len(phraseTokens) == 0 {
+			continue
+		}
+
+		words = append(words, phraseTokens...)
+		phraseWords = append(phraseWords, phraseTokens)
+	}
+
+	words = uniqueWords(words)
+
+	if len(words) == 0 {
+		return nil
+	}
+
+	index.load()
+
+	index.RLock()
+	defer index.RUnlock()
+
+	// Start with the rarest word to keep the candidate set small
+	sort.Slice(words, func(i, j int) bool {
+		return len(index.postings[words[i]]) < len(index.postings[words[j]])
+	})
+
+	var hits []*TextHit
+	averageLength := float64(index.totalLength) / float64(len(index.lengths))
+
+	for id := range index.postings[words[0]] {
+		if !index.containsAll(id, words) || !index.containsPhrases(id, phraseWords) {
+			continue
+		}
+
+		hits = append(hits, &TextHit{
+			ID:    id,
+			Score: index.score(id, words, averageLength),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			return hits[i].ID < hits[j].ID
+		}
+
+		return hits[i].Score > hits[j].Score
+	})
+
+	return hits
+}
+
+// containsAll tells you whether the document contains every word.
+// The index must be locked by the caller.
+func (index *TextIndex) containsAll(id string, words []string) bool {
+	for _, word := range words {
+		if len(index.postings[word][id]) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsPhrases tells you whether the words of every phrase appear consecutively in the document.
+// The index must be locked by the caller.
+func (index *TextIndex) containsPhrases(id string, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		found := false
+
+		for _, start := range index.postings[phrase[0]][id] {
+			if index.matchesPhraseAt(id, phrase, start) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesPhraseAt tells you whether the phrase appears in the document at the given word position.
+func (index *TextIndex) matchesPhraseAt(id string, phrase []string, start int) bool {
+	for offset, word := range phrase[1:] {
+		positions := index.postings[word][id]
+		position := start + offset + 1
+		i := sort.SearchInts(positions, position)
+
+		if i == len(positions) || positions[i] != position {
+			return false
+		}
+	}
+
+	return true
+}
+
+// score calculates the BM25 score of the document for the given words.
+func (index *TextIndex) score(id string, words []string, averageLength float64) float64 {
+	documentCount := float64(len(index.lengths))
+	length := float64(index.lengths[id])
+	score := 0.0
+
+	for _, word := range words {
+		frequency := float64(len(index.postings[word][id]))
+		matchingDocuments := float64(len(index.postings[word]))
+		idf := math.Log(1 + (documentCount-matchingDocuments+0.5)/(matchingDocuments+0.5))
+		score += idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*(1-bm25B+bm25B*length/averageLength))
+	}
+
+	return score
+}
+
+// load fills the index via the loader if that hasn't happened yet.
+// The loader fills a new index that is swapped in when it's complete,
+// so searches during the load don't wait for it and only see the documents added so far.
+// Loaders receive an index that isn't shared yet and therefore use the lock-free add.
+func (index *TextIndex) load() {
+	index.Lock()
+
+	if index.loaded || index.loading {
+		index.Unlock()
+		return
+	}
+
+	if index.loader == nil {
+		index.loaded = true
+		index.Unlock()
+		return
+	}
+
+	index.loading = true
+	index.Unlock()
+
+	loaded := NewTextIndex(nil)
+	index.loader(loaded)
+
+	index.Lock()
+	defer index.Unlock()
+
+	// Apply the changes made in the meantime
+	for _, change := range index.changes {
+		change(loaded)
+	}
+
+	index.postings = loaded.postings
+	index.documents = loaded.documents
+	index.lengths = loaded.lengths
+	index.totalLength = loaded.totalLength
+	index.changes = nil
+	index.loading = false
+	index.loaded = true
+}
+
+// record remembers a change made before the load has been completed
+// so that it can be applied to the loaded index.
+// The index must be locked by the caller.
+func (index *TextIndex) record(change func(*TextIndex)) {
+	if index.loaded || index.loader == nil {
+		return
+	}
+
+	index.changes = append(index.changes, change)
+}
+
+// add is the lock-free version of Add.
+func (index *TextIndex) add(id string, text string) {
+	index.remove(id)
+
+	words := tokenWords(text)
+
+	if len(words) == 0 {
+		return
+	}
+
+	for position, word := range words {
+		documents, exists := index.postings[word]
+
+		if !exists {
+			documents = map[string][]int{}
+			index.postings[word] = documents
+		}
+
+		documents[id] = append(documents[id], position)
+	}
+
+	index.documents[id] = uniqueWords(words)
+	index.lengths[id] = len(words)
+	index.totalLength += len(words)
+}
+
+// remove is the lock-free version of Remove.
+func (index *TextIndex) remove(id string) {
+	words, exists := index.documents[id]
+
+	if !exists {
+		return
+	}
+
+	for _, word := range words {
+		documents := index.postings[word]
+		delete(documents, id)
+
+		if len(documents) == 0 {
+			delete(index.postings, word)
+		}
+	}
+
+	index.totalLength -= index.lengths[id]
+	delete(index.documents, id)
+	delete(index.lengths, id)
+}
+
+// tokenize splits the text into lowercase words.
+// Every kanji and kana character is treated as a separate word
+// because Japanese doesn't use spaces between words.
+func tokenize(text string) []*textToken {
+	var tokens []*textToken
+	start := -1
+
+	finishWord := func(end int) {
+		if start == -1 {
+			return
+		}
+
+		tokens = append(tokens, &textToken{
+			Word:  strings.ToLower(stringutils.FullWidthToHalfWidth(text[start:end])),
+			Start: start,
+			End:   end,
+		})
+
+		start = -1
+	}
+
+	for i, r := range text {
+		switch {
+		case isJapaneseCharacter(r):
+			finishWord(i)
+
+			tokens = append(tokens, &textToken{
+				Word:  stringutils.KatakanaToHiragana(string(r)),
+				Start: i,
+				End:   i + len(string(r)),
+			})
+
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if start == -1 {
+				start = i
+			}
+
+		default:
+			finishWord(i)
+		}
+	}
+
+	finishWord(len(text))
+	return tokens
+}
+
+// tokenWords returns only the words of the tokenized text.
+func tokenWords(text string) []string {
+	tokens := tokenize(text)
+	words := make([]string, len(tokens))
+
+	for i, token := range tokens {
+		words[i] = token.Word
+	}
+
+	return words
+}
+
+// isJapaneseCharacter tells you whether the rune is a kanji or kana character.
+func isJapaneseCharacter(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// uniqueWords returns the words without duplicates, keeping the original order.
+func uniqueWords(words []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(words))
+
+	for _, word := range words {
+		if seen[word] {
+			continue
+		}
+
+		seen[word] = true
+		unique = append(unique, word)
+	}
+
+	return unique
+}
@@ -0,0 +1,68 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTextIndex() *search.TextIndex {
+	index := search.NewTextIndex(nil)
+	index.Add("a", "The new **season** of Attack on Titan starts next week.")
+	index.Add("b", "Season 2 was great, season 3 is even better. Season 4 will be the last season.")
+	index.Add("c", "I prefer the manga over the anime.")
+	index.Add("d", "進撃の巨人 is called Attack on Titan in English.")
+	return index
+}
+
+func ids(hits []*search.TextHit) []string {
+	result := make([]string, len(hits))
+
+	for i, hit := range hits {
+		result[i] = hit.ID
+	}
+
+	return result
+}
+
+func TestTextIndexSearch(t *testing.T) {
+	index := newTestTextIndex()
+
+	assert.Equal(t, 4, index.Count())
+	assert.Equal(t, []string{"b", "a"}, ids(index.Search([]string{"season"}, nil)))
+	assert.Equal(t, []string{"a"}, ids(index.Search([]string{"SEASON", "titan"}, nil)))
+	assert.Equal(t, []string{"c"}, ids(index.Search([]string{"manga"}, nil)))
+	assert.Equal(t, []string{"d"}, ids(index.Search([]string{"巨人"}, nil)))
+	assert.Empty(t, index.Search([]string{"manga", "titan"}, nil))
+	assert.Empty(t, index.Search(nil, nil))
+}
+
+func TestTextIndexPhrases(t *testing.T) {
+	index := newTestTextIndex()
+
+	assert.ElementsMatch(t, []string{"a", "d"}, ids(index.Search(nil, []string{"attack on titan"})))
+	assert.Empty(t, index.Search(nil, []string{"titan on attack"}))
+	assert.Equal(t, []string{"b"}, ids(index.Search([]string{"better"}, []string{"season 3"})))
+	assert.Equal(t, []string{"d"}, ids(index.Search(nil, []string{"進撃の巨人"})))
+	assert.Empty(t, index.Search(nil, []string{"巨人の進撃"}))
+}
+
+func TestTextIndexRemove(t *testing.T) {
+	index := newTestTextIndex()
+	index.Remove("a")
+	index.Add("c", "Titan")
+
+	assert.Equal(t, 3, index.Count())
+	assert.Equal(t, []string{"b"}, ids(index.Search([]string{"season"}, nil)))
+	assert.ElementsMatch(t, []string{"c", "d"}, ids(index.Search([]string{"titan"}, nil)))
+	assert.Empty(t, index.Search([]string{"manga"}, nil))
+}
+
+func TestSnippet(t *testing.T) {
+	text := "One two three four five six seven eight nine ten. The <b>season</b> finale airs on Sunday, after that the season is over."
+
+	assert.Equal(t, "…ten. The &lt;b&gt;<mark>season</mark>&lt;/b&gt; finale airs on Sunday, after that the <mark>season</mark> is…", search.Snippet(text, "Season", 14))
+	assert.Equal(t, "One two three…", search.Snippet(text, "missing", 3))
+	assert.Equal(t, "", search.Snippet("", "season", 10))
+}
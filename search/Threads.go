@@ -10,6 +10,18 @@ import (
 
 // Threads searches all threads.
 func Threads(originalTerm string, maxLength int) []*arn.Thread {
+	return searchThreads(originalTerm, nil, maxLength)
+}
+
+// ThreadsByQuery searches all threads that match the filters and phrases of the query.
+// An empty text matches every thread, so filters alone are sufficient.
+func ThreadsByQuery(query *Query, maxLength int) []*arn.Thread {
+	return searchThreads(query.Text(), query.MatchesThread, maxLength)
+}
+
+// searchThreads searches the text and title of threads.
+// If a filter is specified, only threads passing the filter are considered.
+func searchThreads(originalTerm string, filter func(*arn.Thread) bool, maxLength int) []*arn.Thread {
 	term := strings.ToLower(stringutils.RemoveSpecialCharacters(originalTerm))
 
 	var results []*arn.Thread
@@ -19,6 +31,10 @@ func Threads(originalTerm string, maxLength int) []*arn.Thread {
 			return []*arn.Thread{thread}
 		}
 
+		if filter != nil && !filter(thread) {
+			continue
+		}
+
 		text := strings.ToLower(thread.Text)
 
 		if strings.Contains(text, term) {
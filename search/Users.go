@@ -10,15 +10,23 @@ import (
 
 // Users searches all users.
 func Users(originalTerm string, maxLength int) []*arn.User {
-	term := strings.ToLower(stringutils.RemoveSpecialCharacters(originalTerm))
+	term := normalize(originalTerm)
 
 	var results []*Result
 
-	for user := range arn.StreamUsers() {
-		if user.ID == originalTerm {
-			return []*arn.User{user}
+	// Exact ID match
+	exact, err := arn.GetUser(originalTerm)
+
+	if err == nil {
+		return []*arn.User{exact}
+	}
+
+	for _, obj := range arn.DB.GetMany("User", userIndex.Search(term)) {
+		if obj == nil {
+			continue
 		}
 
+		user := obj.(*arn.User)
 		text := strings.ToLower(user.Nick)
 
 		// Similarity check
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"math"
+	"sort"
+)
+
+// Vocabulary contains the words of an index mapped to their number of occurrences.
+// The words are indexed by their trigrams to find spelling corrections quickly.
+type Vocabulary struct {
+	Counts   map[string]int
+	trigrams map[string][]string
+}
+
+// newVocabulary creates the vocabulary for the given word counts.
+func newVocabulary(counts map[string]int) *Vocabulary {
+	vocabulary := &Vocabulary{
+		Counts:   counts,
+		trigrams: map[string][]string{},
+	}
+
+	for word := range counts {
+		for trigram := range trigrams(word) {
+			vocabulary.trigrams[trigram] = append(vocabulary.trigrams[trigram], word)
+		}
+	}
+
+	return vocabulary
+}
+
+// Candidates returns the words that share enough trigrams with the given word
+// to be a possible spelling correction, sorted alphabetically.
+func (vocabulary *Vocabulary) Candidates(word string) []string {
+	wordTrigrams := trigrams(word)
+	required := int(math.Ceil(float64(len(wordTrigrams)) * minimumTrigramOverlap))
+	counts := map[string]int{}
+
+	for trigram := range wordTrigrams {
+		for _, candidate := range vocabulary.trigrams[trigram] {
+			counts[candidate]++
+		}
+	}
+
+	candidates := make([]string, 0, len(counts))
+
+	for candidate, count := range counts {
+		if count >= required {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.Strings(candidates)
+	return candidates
+}
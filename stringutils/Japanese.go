@@ -0,0 +1,155 @@
+package stringutils
+
+import "strings"
+
+// Offsets between the different character ranges.
+const (
+	katakanaToHiraganaOffset  = 'ァ' - 'ぁ'
+	fullWidthToHalfWidthDelta = '！' - '!'
+)
+
+// hiraganaToRomaji maps every hiragana syllable to its Hepburn romanization.
+var hiraganaToRomaji = map[string]string{
+	"あ": "a", "い": "i", "う": "u", "え": "e", "お": "o",
+	"か": "ka", "き": "ki", "く": "ku", "け": "ke", "こ": "ko",
+	"さ": "sa", "し": "shi", "す": "su", "せ": "se", "そ": "so",
+	"た": "ta", "ち": "chi", "つ": "tsu", "て": "te", "と": "to",
+	"な": "na", "に": "ni", "ぬ": "nu", "ね": "ne", "の": "no",
+	"は": "ha", "ひ": "hi", "ふ": "fu", "へ": "he", "ほ": "ho",
+	"ま": "ma", "み": "mi", "む": "mu", "め": "me", "も": "mo",
+	"や": "ya", "ゆ": "yu", "よ": "yo",
+	"ら": "ra", "り": "ri", "る": "ru", "れ": "re", "ろ": "ro",
+	"わ": "wa", "ゐ": "i", "ゑ": "e", "を": "wo", "ん": "n",
+	"が": "ga", "ぎ": "gi", "ぐ": "gu", "げ": "ge", "ご": "go",
+	"ざ": "za", "じ": "ji", "ず": "zu", "ぜ": "ze", "ぞ": "zo",
+	"だ": "da", "ぢ": "ji", "づ": "zu", "で": "de", "ど": "do",
+	"ば": "ba", "び": "bi", "ぶ": "bu", "べ": "be", "ぼ": "bo",
+	"ぱ": "pa", "ぴ": "pi", "ぷ": "pu", "ぺ": "pe", "ぽ": "po",
+	"ゔ": "vu",
+	"ぁ": "a", "ぃ": "i", "ぅ": "u", "ぇ": "e", "ぉ": "o",
+	"ゃ": "ya", "ゅ": "yu", "ょ": "yo", "ゎ": "wa",
+	"きゃ": "kya", "きゅ": "kyu", "きょ": "kyo",
+	"しゃ": "sha", "しゅ": "shu", "しょ": "sho", "しぇ": "she",
+	"ちゃ": "cha", "ちゅ": "chu", "ちょ": "cho", "ちぇ": "che",
+	"にゃ": "nya", "にゅ": "nyu", "にょ": "nyo",
+	"ひゃ": "hya", "ひゅ": "hyu", "ひょ": "hyo",
+	"みゃ": "mya", "みゅ": "myu", "みょ": "myo",
+	"りゃ": "rya", "りゅ": "ryu", "りょ": "ryo",
+	"ぎゃ": "gya", "ぎゅ": "gyu", "ぎょ": "gyo",
+	"じゃ": "ja", "じゅ": "ju", "じょ": "jo", "じぇ": "je",
+	"ぢゃ": "ja", "ぢゅ": "ju", "ぢょ": "jo",
+	"びゃ": "bya", "びゅ": "byu", "びょ": "byo",
+	"ぴゃ": "pya", "ぴゅ": "pyu", "ぴょ": "pyo",
+	"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo",
+	"てぃ": "ti", "でぃ": "di", "とぅ": "tu", "どぅ": "du",
+	"うぃ": "wi", "うぇ": "we", "うぉ": "wo",
+	"ゔぁ": "va", "ゔぃ": "vi", "ゔぇ": "ve", "ゔぉ": "vo",
+}
+
+// FullWidthToHalfWidth converts full-width latin letters, digits and symbols to their ASCII versions.
+func FullWidthToHalfWidth(s string) string {
+	return strings.Map(
+		func(r rune) rune {
+			switch {
+			case r >= '！' && r <= '～':
+				return r - fullWidthToHalfWidthDelta
+			case r == '　':
+				return whitespace
+			default:
+				return r
+			}
+		},
+		s,
+	)
+}
+
+// KatakanaToHiragana converts all katakana characters to hiragana.
+func KatakanaToHiragana(s string) string {
+	return strings.Map(
+		func(r rune) rune {
+			if r >= 'ァ' && r <= 'ヶ' {
+				return r - katakanaToHiraganaOffset
+			}
+
+			return r
+		},
+		s,
+	)
+}
+
+// KanaToRomaji converts hiragana and katakana to Hepburn romanization.
+// Characters that aren't kana, e.g. kanji or latin letters, are left untouched.
+func KanaToRomaji(s string) string {
+	runes := []rune(KatakanaToHiragana(s))
+	result := strings.Builder{}
+	doubleConsonant := false
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		// Small tsu doubles the next consonant
+		if r == 'っ' {
+			doubleConsonant = true
+			continue
+		}
+
+		// Long vowel mark repeats the previous vowel
+		if r == 'ー' {
+			written := result.String()
+
+			if written != "" {
+				last := rune(written[len(written)-1])
+
+				if strings.ContainsRune("aiueo", last) {
+					result.WriteRune(last)
+				}
+			}
+
+			continue
+		}
+
+		romaji := ""
+
+		// Try syllables with small kana first
+		if i+1 < len(runes) {
+			romaji = hiraganaToRomaji[string(runes[i:i+2])]
+
+			if romaji != "" {
+				i++
+			}
+		}
+
+		if romaji == "" {
+			romaji = hiraganaToRomaji[string(r)]
+		}
+
+		if romaji == "" {
+			doubleConsonant = false
+			result.WriteRune(r)
+			continue
+		}
+
+		if doubleConsonant {
+			doubleConsonant = false
+			first := romaji[0]
+
+			if strings.HasPrefix(romaji, "ch") {
+				first = 't'
+			}
+
+			if !strings.ContainsRune("aiueon", rune(first)) {
+				result.WriteByte(first)
+			}
+		}
+
+		result.WriteString(romaji)
+	}
+
+	return result.String()
+}
+
+// NormalizeJapanese folds full-width characters and converts kana to romaji
+// so that hiragana, katakana and romaji versions of a text become comparable.
+func NormalizeJapanese(s string) string {
+	return KanaToRomaji(FullWidthToHalfWidth(s))
+}
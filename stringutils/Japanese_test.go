@@ -0,0 +1,34 @@
+package stringutils_test
+
+import (
+	"testing"
+
+	"github.com/animenotifier/arn/stringutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullWidthToHalfWidth(t *testing.T) {
+	assert.Equal(t, "K-On! 2", stringutils.FullWidthToHalfWidth("Ｋ－Ｏｎ！　２"))
+	assert.Equal(t, "けいおん", stringutils.FullWidthToHalfWidth("けいおん"))
+}
+
+func TestKatakanaToHiragana(t *testing.T) {
+	assert.Equal(t, "けいおん", stringutils.KatakanaToHiragana("ケイオン"))
+	assert.Equal(t, "どらごんぼーる", stringutils.KatakanaToHiragana("ドラゴンボール"))
+	assert.Equal(t, "進撃の巨人", stringutils.KatakanaToHiragana("進撃の巨人"))
+}
+
+func TestKanaToRomaji(t *testing.T) {
+	assert.Equal(t, "keion", stringutils.KanaToRomaji("けいおん"))
+	assert.Equal(t, "keion", stringutils.KanaToRomaji("ケイオン"))
+	assert.Equal(t, "doragonbooru", stringutils.KanaToRomaji("ドラゴンボール"))
+	assert.Equal(t, "shingeki no kyojin", stringutils.KanaToRomaji("しんげき の きょじん"))
+	assert.Equal(t, "matchi", stringutils.KanaToRomaji("まっち"))
+	assert.Equal(t, "gakkou", stringutils.KanaToRomaji("がっこう"))
+	assert.Equal(t, "fate", stringutils.KanaToRomaji("fate"))
+	assert.Equal(t, "君no名ha", stringutils.KanaToRomaji("君の名は"))
+}
+
+func TestNormalizeJapanese(t *testing.T) {
+	assert.Equal(t, stringutils.NormalizeJapanese("けいおん"), stringutils.NormalizeJapanese("ｋｅｉｏｎ"))
+}
@@ -109,3 +109,42 @@ func keepLettersAndDigits(r rune) rune {
 
 	return r
 }
+
+// EditDistance returns the Levenshtein distance between both strings,
+// which is the number of single character edits needed to turn a into b.
+func EditDistance(a string, b string) int {
+	runesA := []rune(a)
+	runesB := []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j-1] + cost
+
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(runesB)]
+}
@@ -24,3 +24,11 @@ func TestContainsUnicodeLetters(t *testing.T) {
 	assert.True(t, stringutils.ContainsUnicodeLetters("こんにちは"))
 	assert.True(t, stringutils.ContainsUnicodeLetters("hello こんにちは"))
 }
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, stringutils.EditDistance("dragon ball", "dragon ball"))
+	assert.Equal(t, 1, stringutils.EditDistance("dragn ball", "dragon ball"))
+	assert.Equal(t, 3, stringutils.EditDistance("kitten", "sitting"))
+	assert.Equal(t, 5, stringutils.EditDistance("", "hello"))
+	assert.Equal(t, 1, stringutils.EditDistance("けいおん", "けいおん!"))
+}